@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
 
 	"github.com/jamesdobson/gogitmoji/tmpl"
 )
@@ -22,7 +24,7 @@ const (
 
 // commitCmd represents the commit command
 var commitCmd = &cobra.Command{
-	Use:     "commit",
+	Use:     "commit [flags] [-- extra git args]",
 	Aliases: []string{"hook"},
 	Short:   "⚡️  Compose a commit message and execute git commit (default command)",
 	Long: `Compose a commit message and execute git commit.
@@ -32,19 +34,44 @@ all prompts are filled out, executes git commit.
 
 This is the default command when no other command is specified to gogitmoji.
 
+Prompts can be answered in advance with --answer name=value, which may be
+given more than once, or with --answers and a YAML file mapping prompt names
+to answers; --answer wins when both answer the same prompt. The answer to a
+gitmoji prompt may be its code, emoji or name; the answer to a confirm prompt
+may be yes or no; and the answer to a multichoice prompt may be a list, or
+values separated by commas. When any answers are given, only the mandatory
+prompts that they don't answer are asked. With --no-input, nothing is asked, the command is
+executed without confirmation, and it is an error for a mandatory prompt to
+have no answer.
+
+Arguments after "--" are added to the end of the git commit command, e.g.
+"gitmoji commit -- --amend --no-verify", or "gitmoji commit -- -- file.go" to
+commit only some files.
+
+With --dry-run, the command is shown but not executed. With --print, the
+commit message is printed on standard output instead, with a blank line
+between paragraphs, so that it can be piped into "git commit -F -"; prompts
+are then shown on standard error.
+
 The hook do command has the same format, except that it takes one argument: the
 path to a file containing the commit message. The hook do command is only
 intended to be called by a commit hook; not directly from the CLI. There must
 be a space between "hook" and "do."`,
 	Run: func(cmd *cobra.Command, args []string) {
+		opts := getTemplateOptions(cmd)
+
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 && cmd.CalledAs() != "hook" {
+			opts.ExtraArgs = args[dash:]
+		}
+
 		if cmd.CalledAs() == "hook" {
 			if len(args) == 2 && args[0] == "do" {
-				do(args[1:])
+				do(args[1:], opts)
 			} else {
 				log.Fatalf("Argument to hook must be 'do', followed by path to commit message file.")
 			}
 		} else {
-			commit()
+			commit(opts)
 		}
 	},
 }
@@ -57,6 +84,12 @@ func init() {
 	commitCmd.Flags().StringP("format", "f", formatAsEmoji, `Emoji format; either "emoji" or "code".`)
 	commitCmd.Flags().BoolP("scope", "p", false, "Enable scope prompt")
 	commitCmd.Flags().StringP("template", "t", tmpl.DefaultTemplateName, `Commit template name.`)
+	commitCmd.Flags().StringArray("answer", nil, "Answer a prompt in advance, as name=value")
+	commitCmd.Flags().String("answers", "", "YAML file of answers to prompts, by prompt name")
+	commitCmd.Flags().Bool("no-input", false, "Don't prompt; fail if a mandatory prompt has no answer")
+	commitCmd.Flags().Bool("dry-run", false, "Show the command that would be executed, without executing it")
+	commitCmd.Flags().Bool("print", false, "Print the commit message instead of executing the command")
+	commitCmd.MarkFlagsMutuallyExclusive("dry-run", "print")
 
 	err = viper.BindPFlag(formatSetting, commitCmd.Flags().Lookup("format"))
 	if err != nil {
@@ -74,28 +107,109 @@ func init() {
 	}
 }
 
-func commit() {
+// getTemplateOptions reads the answers given in advance from the command's
+// flags.
+func getTemplateOptions(cmd *cobra.Command) tmpl.Options {
+	answerFlags, err := cmd.Flags().GetStringArray("answer")
+
+	if err != nil {
+		panic(err)
+	}
+
+	answersFile, err := cmd.Flags().GetString("answers")
+
+	if err != nil {
+		panic(err)
+	}
+
+	noInput, err := cmd.Flags().GetBool("no-input")
+
+	if err != nil {
+		panic(err)
+	}
+
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+
+	if err != nil {
+		panic(err)
+	}
+
+	printOnly, err := cmd.Flags().GetBool("print")
+
+	if err != nil {
+		panic(err)
+	}
+
+	answers := map[string]interface{}{}
+
+	if answersFile != "" {
+		answers, err = readAnswersFile(answersFile)
+
+		if err != nil {
+			log.Fatalf("Error reading answers file: %v", err)
+		}
+	}
+
+	for _, answer := range answerFlags {
+		name, value, found := strings.Cut(answer, "=")
+
+		if !found || name == "" {
+			log.Fatalf("Invalid answer '%s': must be name=value", answer)
+		}
+
+		answers[name] = value
+	}
+
+	return tmpl.Options{
+		Answers: answers,
+		NoInput: noInput,
+		DryRun:  dryRun,
+		Print:   printOnly,
+	}
+}
+
+func readAnswersFile(file string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	answers := map[string]interface{}{}
+	err = yaml.Unmarshal(data, &answers)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse '%s': %v", file, err)
+	}
+
+	return answers, nil
+}
+
+func commit(opts tmpl.Options) {
 	templates := viper.GetStringMap("templates")
 	t := viper.GetString(templateSetting)
 
 	tmpl.LoadTemplates(templates)
 
 	if tpl, ok := tmpl.TemplateLookup[t]; ok {
-		tmpl.RunTemplateCommand(tpl)
-		fmt.Printf("\ngogitmoji done.\n")
+		tmpl.RunTemplateCommand(tpl, opts)
+
+		if !opts.DryRun && !opts.Print {
+			fmt.Printf("\ngogitmoji done.\n")
+		}
 	} else {
 		log.Fatalf("Unknown commit template: \"%s\"\n", t)
 	}
 }
 
-func do(args []string) {
+func do(args []string, opts tmpl.Options) {
 	templates := viper.GetStringMap("templates")
 	t := viper.GetString(templateSetting)
 
 	tmpl.LoadTemplates(templates)
 
 	if tpl, ok := tmpl.TemplateLookup[t]; ok {
-		msg := tmpl.GetTemplateMessage(tpl)
+		msg := tmpl.GetTemplateMessage(tpl, opts)
 		f, err := os.OpenFile(args[0], os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 
 		if err != nil {
@@ -17,8 +17,11 @@ var listCmd = &cobra.Command{
 	Long: `List all available gitmoji.
 
 The gitmoji are printed on standard output, one gitmoji per line. Each line
-has the emoji itself, the emoji's code, and a description of when to use
-it.`,
+has the emoji itself, the emoji's code, a description of when to use it and,
+in brackets, the kind of semantic version change it implies, if any.
+
+If the list cannot be downloaded, the snapshot of the list that is built into
+gogitmoji is printed instead, along with a note saying so.`,
 	Run: func(*cobra.Command, []string) {
 		list()
 	},
@@ -42,13 +45,30 @@ func list() {
 	}
 
 	cyan := color.New(color.FgCyan)
+	faint := color.New(color.Faint)
 
 	for i := 0; i < len(gitmojiList); i++ {
 		gitmoji := gitmojiList[i]
 		fmt.Printf("%s  - ", gitmoji.Emoji)
 		cyan.Printf("%s", gitmoji.Code)
-		fmt.Printf(" %s\n", gitmoji.Description)
+		fmt.Printf(" %s", gitmoji.Description)
+
+		if gitmoji.Semver != "" {
+			faint.Printf(" [%s]", gitmoji.Semver)
+		}
+
+		fmt.Println("")
 	}
 
 	fmt.Println("")
+
+	if cache.UsingBuiltin() {
+		fmt.Printf("ℹ️  This is the built-in list of gitmoji, which may be out of date. Run \"gitmoji update\" once online.\n\n")
+	}
+
+	err = cache.WaitForRefresh()
+
+	if err != nil {
+		fmt.Printf("⚠️  %v\n\n", err)
+	}
 }
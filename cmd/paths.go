@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/jamesdobson/gogitmoji/gitmoji"
+	"github.com/jamesdobson/gogitmoji/history"
+	"github.com/jamesdobson/gogitmoji/i18n"
+	"github.com/jamesdobson/gogitmoji/xdg"
+)
+
+// pathsCmd represents the paths command
+var pathsCmd = &cobra.Command{
+	Use:   "paths",
+	Short: "🗂  Show where gogitmoji keeps its files",
+	Long: `Show where gogitmoji keeps its files.
+
+Prints the location of each file that gogitmoji reads or writes, and whether
+it currently exists.
+
+Files are kept in the directories given by the XDG Base Directory
+Specification: $XDG_CONFIG_HOME/gitmoji (default ~/.config/gitmoji) for
+configuration, $XDG_CACHE_HOME/gitmoji (default ~/.cache/gitmoji) for the
+list of gitmoji, and $XDG_STATE_HOME/gitmoji (default ~/.local/state/gitmoji)
+for the history of chosen gitmoji. Files are still read from the legacy
+~/.gitmoji directory until they are moved.`,
+	Run: func(*cobra.Command, []string) {
+		paths()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pathsCmd)
+}
+
+func paths() {
+	configFile := viper.ConfigFileUsed()
+
+	if configFile == "" {
+		configDir, err := xdg.ConfigDir()
+
+		if err != nil {
+			log.Fatalf("Unable to locate config file: %v", err)
+		}
+
+		configFile = filepath.Join(configDir, configName+".yaml")
+	}
+
+	cacheFile, _, err := gitmoji.DefaultCacheFile()
+
+	if err != nil {
+		log.Fatalf("Unable to locate gitmoji cache: %v", err)
+	}
+
+	printPath("Config file", configFile)
+
+	if repoConfigFile, err := getRepoConfigFile(); err == nil {
+		printPath("Repository config file", repoConfigFile)
+	}
+
+	printPath("Gitmoji list", cacheFile)
+	printPath("Gitmoji list metadata", cacheFile+gitmoji.MetaFileSuffix)
+	printPath("Gitmoji list lock", cacheFile+gitmoji.LockFileSuffix)
+
+	if historyFile, err := history.DefaultFile(); err == nil {
+		printPath("History", historyFile)
+	}
+
+	if localesDir, err := i18n.LocalesDir(); err == nil {
+		printPath("Translations", localesDir)
+	}
+
+	fmt.Println("")
+}
+
+func printPath(description string, file string) {
+	cyan := color.New(color.FgCyan)
+	faint := color.New(color.Faint)
+
+	fmt.Printf("%-24s ", description+":")
+	cyan.Printf("%s", file)
+
+	if _, err := os.Stat(file); err != nil {
+		faint.Printf(" (not found)")
+	}
+
+	fmt.Println("")
+}
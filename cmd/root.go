@@ -4,21 +4,46 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/jamesdobson/gogitmoji/git"
+	"github.com/jamesdobson/gogitmoji/gitmoji"
+	"github.com/jamesdobson/gogitmoji/i18n"
+	"github.com/jamesdobson/gogitmoji/suggest"
+	"github.com/jamesdobson/gogitmoji/tmpl"
+	"github.com/jamesdobson/gogitmoji/xdg"
 )
 
 var cfgFile string
 
+// configName is the name of the user's config file, less its extension.
+const configName = "config"
+
+// repoConfigFileName is the name of the per-repository config file, which is
+// found at the root of the working tree.
+const repoConfigFileName = ".gitmoji.yaml"
+
+// repoSettings are the settings that may be given in a per-repository config
+// file. Since anyone who can commit to a repository can change this file, the
+// settings are limited to ones that cannot cause commands to be run.
+var repoSettings = []string{
+	gitmoji.CustomSetting,
+	suggest.RulesSetting,
+	suggest.BuiltinSetting,
+	suggest.ScopesSetting,
+	tmpl.IssuePatternSetting,
+}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "gitmoji",
 	Short: "Gitmoji helper written in Go.",
 	Long:  `gogitmoji helps you write git commit messages containing gitmoji!`,
 	Run: func(*cobra.Command, []string) {
-		commit()
+		commit(tmpl.Options{})
 	},
 }
 
@@ -39,7 +64,7 @@ func Execute() {
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.gitmoji/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $XDG_CONFIG_HOME/gitmoji/config.yaml)")
 
 	setHelpEmoji()
 }
@@ -65,15 +90,22 @@ func initConfig() {
 		// Use config file from the flag.
 		viper.SetConfigFile(cfgFile)
 	} else {
-		// Find home directory.
-		home, err := os.UserHomeDir()
+		// Search the XDG config directory, then the legacy directory
+		configDir, err := xdg.ConfigDir()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		legacyDir, err := xdg.LegacyDir()
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
 
-		viper.AddConfigPath(path.Join(home, ".gitmoji"))
-		viper.SetConfigName("config")
+		viper.AddConfigPath(configDir)
+		viper.AddConfigPath(legacyDir)
+		viper.SetConfigName(configName)
 	}
 
 	viper.SetEnvPrefix("gitmoji")
@@ -81,7 +113,7 @@ func initConfig() {
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	} else {
 		switch err.(type) {
 		case viper.ConfigFileNotFoundError:
@@ -90,4 +122,90 @@ func initConfig() {
 			log.Fatalf("Error reading '%s': %v", viper.ConfigFileUsed(), err)
 		}
 	}
+
+	printMigrationNotices()
+	mergeRepoConfig()
+
+	if err := i18n.Load(); err != nil {
+		log.Fatalf("Error loading translations: %v", err)
+	}
+}
+
+// printMigrationNotices suggests moving files that were found in the legacy
+// ~/.gitmoji directory to their XDG base directories.
+func printMigrationNotices() {
+	legacyDir, err := xdg.LegacyDir()
+
+	if err != nil {
+		return
+	}
+
+	if cfgFile == "" && viper.ConfigFileUsed() != "" && filepath.Dir(viper.ConfigFileUsed()) == legacyDir {
+		if configDir, err := xdg.ConfigDir(); err == nil {
+			printMigrationNotice(viper.ConfigFileUsed(), configDir)
+		}
+	}
+
+	if cacheFile, legacy, err := gitmoji.DefaultCacheFile(); err == nil && legacy {
+		if cacheDir, err := xdg.CacheDir(); err == nil {
+			printMigrationNotice(cacheFile, cacheDir)
+		}
+	}
+}
+
+func printMigrationNotice(file string, dir string) {
+	fmt.Fprintf(os.Stderr, "ℹ️  %s is in a legacy location; please move it to %s\n", file, dir)
+}
+
+// mergeRepoConfig reads the per-repository config file, if there is one, and
+// merges its settings into the configuration. Lists are appended to the
+// corresponding lists from the user's config file; other values replace them.
+func mergeRepoConfig() {
+	repoConfigFile, err := getRepoConfigFile()
+
+	if err != nil {
+		// Not in a git repository
+		return
+	}
+
+	repoConfig := viper.New()
+	repoConfig.SetConfigFile(repoConfigFile)
+
+	if err := repoConfig.ReadInConfig(); err != nil {
+		if os.IsNotExist(err) {
+			return
+		}
+
+		log.Fatalf("Error reading '%s': %v", repoConfig.ConfigFileUsed(), err)
+	}
+
+	fmt.Fprintln(os.Stderr, "Using repository config file:", repoConfig.ConfigFileUsed())
+
+	for _, key := range repoSettings {
+		if !repoConfig.IsSet(key) {
+			continue
+		}
+
+		value := repoConfig.Get(key)
+		repoList, isList := value.([]interface{})
+		userList, userIsList := viper.Get(key).([]interface{})
+
+		if isList && userIsList {
+			value = append(append([]interface{}{}, userList...), repoList...)
+		}
+
+		viper.Set(key, value)
+	}
+}
+
+// getRepoConfigFile returns the path of the per-repository config file for the
+// current working tree, whether it exists or not.
+func getRepoConfigFile() (string, error) {
+	topLevel, err := git.TopLevel()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(topLevel, repoConfigFileName), nil
 }
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/jamesdobson/gogitmoji/gitmoji"
+	"github.com/jamesdobson/gogitmoji/history"
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "📊  Show which gitmoji you use most",
+	Long: `Show which gitmoji you use most.
+
+Each time a gitmoji is chosen in a commit, it is recorded in a history file,
+and the gitmoji used most often and most recently are offered first the next
+time. This prints the gitmoji in the history, in that order, with the number
+of times each was chosen and when it was last chosen.
+
+When the history.per-repo setting is true, each repository has its own
+history, and the history of the current repository is shown.
+
+With --reset, the history is cleared instead.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		reset, err := cmd.Flags().GetBool("reset")
+
+		if err != nil {
+			panic(err)
+		}
+
+		stats(reset)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().Bool("reset", false, "Clear the history of chosen gitmoji")
+}
+
+func stats(reset bool) {
+	hist, err := history.New()
+
+	if err != nil {
+		log.Fatalf("Unable to read history: %v", err)
+	}
+
+	if reset {
+		if err := hist.Reset(); err != nil {
+			log.Fatalf("Unable to reset history: %v", err)
+		}
+
+		fmt.Printf("History of chosen gitmoji cleared. 🧹\n")
+		return
+	}
+
+	uses := hist.Uses(time.Now())
+
+	if len(uses) == 0 {
+		fmt.Printf("No gitmoji have been chosen yet.\n")
+		return
+	}
+
+	emoji := map[string]string{}
+
+	if cache, err := gitmoji.NewCache(); err == nil {
+		if list, err := cache.GetGitmoji(); err == nil {
+			for _, g := range list {
+				emoji[g.Code] = g.Emoji
+			}
+		}
+
+		if err := cache.WaitForRefresh(); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+
+	cyan := color.New(color.FgCyan)
+	faint := color.New(color.Faint)
+
+	for _, use := range uses {
+		fmt.Printf("%s  - ", emoji[use.Code])
+		cyan.Printf("%-30s", use.Code)
+		fmt.Printf(" %5d", use.Count)
+		faint.Printf("  last used %s", use.Last.Format("2006-01-02"))
+		fmt.Println("")
+	}
+
+	fmt.Println("")
+}
@@ -1,29 +1,143 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
+	"os"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/jamesdobson/gogitmoji/gitmoji"
 )
 
+// updateResult is the output of the update command in JSON format.
+type updateResult struct {
+	Source  string `json:"source"`
+	Updated bool   `json:"updated"`
+	gitmoji.Diff
+}
+
 // updateCmd represents the update command
 var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "🔄  Update the list of gitmoji",
 	Long: `Update the list of gitmoji.
 
-Downloads a new list of gitmoji from https://gitmoji.carloscuesta.me/.`,
-	Run: func(*cobra.Command, []string) {
-		err := gitmoji.UpdateCache()
+Downloads a new list of gitmoji from https://gitmoji.carloscuesta.me/, or from
+the sources given by the gitmoji.sources setting. Each source is tried in
+order until one succeeds.
+
+Prints the gitmoji that were added, removed, renamed (that is, given a new
+code) or given a new description. With --json, the result is printed as a
+JSON object instead.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		asJSON, err := cmd.Flags().GetBool("json")
 
 		if err != nil {
-			log.Fatalf("Unable to update: %v", err)
+			panic(err)
 		}
+
+		update(asJSON)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(updateCmd)
+
+	updateCmd.Flags().Bool("json", false, "Print the changes to the list of gitmoji as JSON")
+}
+
+func update(asJSON bool) {
+	cache, err := gitmoji.NewCache()
+
+	if err != nil {
+		log.Fatalf("Unable to update: %v", err)
+	}
+
+	if !asJSON {
+		fmt.Println("🌐  Fetching list of gitmoji...")
+	}
+
+	diff, updated, err := cache.Update()
+
+	if err != nil {
+		log.Fatalf("Unable to update: %v", err)
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		err = encoder.Encode(updateResult{
+			Source:  cache.Source(),
+			Updated: updated,
+			Diff:    diff,
+		})
+
+		if err != nil {
+			log.Fatalf("\nUnable to write output: %v\n\n", err)
+		}
+
+		return
+	}
+
+	if !updated {
+		fmt.Printf("List of gitmoji from %s is already up to date. 👍\n", cache.Source())
+		return
+	}
+
+	fmt.Printf("List of gitmoji updated from %s! 🎉\n", cache.Source())
+	printDiff(diff)
+}
+
+func printDiff(diff gitmoji.Diff) {
+	cyan := color.New(color.FgCyan)
+	faint := color.New(color.Faint)
+
+	if len(diff.Added) > 0 {
+		fmt.Printf("\nAdded:\n")
+
+		for _, g := range diff.Added {
+			fmt.Printf("  %s  - ", g.Emoji)
+			cyan.Printf("%s", g.Code)
+			fmt.Printf(" %s\n", g.Description)
+		}
+	}
+
+	if len(diff.Removed) > 0 {
+		fmt.Printf("\nRemoved:\n")
+
+		for _, g := range diff.Removed {
+			fmt.Printf("  %s  - ", g.Emoji)
+			cyan.Printf("%s", g.Code)
+			fmt.Printf(" %s\n", g.Description)
+		}
+	}
+
+	if len(diff.Renamed) > 0 {
+		fmt.Printf("\nRenamed:\n")
+
+		for _, c := range diff.Renamed {
+			fmt.Printf("  %s  - ", c.New.Emoji)
+			cyan.Printf("%s", c.Old.Code)
+			faint.Printf(" → ")
+			cyan.Printf("%s", c.New.Code)
+			fmt.Printf(" %s\n", c.New.Description)
+		}
+	}
+
+	if len(diff.Changed) > 0 {
+		fmt.Printf("\nNew descriptions:\n")
+
+		for _, c := range diff.Changed {
+			fmt.Printf("  %s  - ", c.New.Emoji)
+			cyan.Printf("%s", c.New.Code)
+			faint.Printf(" %s →", c.Old.Description)
+			fmt.Printf(" %s\n", c.New.Description)
+		}
+	}
+
+	fmt.Println("")
 }
@@ -0,0 +1,87 @@
+// Package git runs git commands to find out about the repository that
+// gogitmoji is being run in.
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// TopLevel returns the path to the root of the current working tree.
+func TopLevel() (string, error) {
+	return output("rev-parse", "--show-toplevel")
+}
+
+// CurrentBranch returns the short name of the branch that is checked out, or
+// an error if HEAD is detached.
+func CurrentBranch() (string, error) {
+	return output("symbolic-ref", "--short", "HEAD")
+}
+
+// LastSubject returns the subject of the commit at HEAD.
+func LastSubject() (string, error) {
+	return output("log", "-1", "--format=%s")
+}
+
+// output runs git with the given arguments and returns its standard output,
+// less the trailing newline.
+func output(args ...string) (string, error) {
+	// #nosec G204
+	out, err := exec.Command("git", args...).Output()
+
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+
+		return "", fmt.Errorf("unable to run git: %v", err)
+	}
+
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// Change describes a file in a diff, and how many lines were added to and
+// removed from it. Binary files have no line counts.
+type Change struct {
+	Path    string
+	Added   int
+	Removed int
+}
+
+// StagedChanges returns the files changed in the index, compared to HEAD.
+func StagedChanges() ([]Change, error) {
+	out, err := output("diff", "--cached", "--numstat", "--no-renames")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNumstat(out), nil
+}
+
+// parseNumstat parses the output of git diff --numstat, where each line has
+// the number of added lines, the number of removed lines and the path,
+// separated by tabs. Binary files have "-" instead of line counts.
+func parseNumstat(out string) []Change {
+	var changes []Change
+
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+
+		if len(fields) != 3 {
+			continue
+		}
+
+		added, _ := strconv.Atoi(fields[0])
+		removed, _ := strconv.Atoi(fields[1])
+		changes = append(changes, Change{
+			Path:    fields[2],
+			Added:   added,
+			Removed: removed,
+		})
+	}
+
+	return changes
+}
@@ -0,0 +1,13 @@
+package gitmoji
+
+import (
+	// Needed for go:embed
+	_ "embed"
+)
+
+// builtinGitmoji is a snapshot of the upstream gitmoji list, compiled into the
+// program so that there is always a list to fall back on when the list can
+// neither be read from the local cache nor downloaded.
+//
+//go:embed gitmojis.json
+var builtinGitmoji []byte
@@ -3,30 +3,84 @@ package gitmoji
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
-	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/jamesdobson/gogitmoji/i18n"
+	"github.com/jamesdobson/gogitmoji/xdg"
 )
 
 // GitmojiURL is the address from which to download the list of gitmoji.
 const GitmojiURL string = "https://raw.githubusercontent.com/carloscuesta/gitmoji/master/packages/gitmojis/src/gitmojis.json"
 
-// GitmojiDirName is the name of the directory under the user's home directory to store the gitmoji list.
-const GitmojiDirName string = ".gitmoji"
+// SourcesSetting is the name of the setting listing the addresses from which
+// to download the list of gitmoji, in order of preference. Each address is
+// either a URL or a file:// path. Defaults to GitmojiURL.
+const SourcesSetting string = "gitmoji.sources"
+
+// GitmojiDirName is the name of the directory under the user's home directory
+// where the gitmoji list used to be stored.
+//
+// Deprecated: the list is stored in the user's cache directory; see
+// DefaultCacheFile. This is xdg.LegacyDirName.
+const GitmojiDirName string = xdg.LegacyDirName
 
 // GitmojiFileName is the name of the file to store the list of gitmoji.
 const GitmojiFileName string = "gitmojis.json"
 
+// TTLSetting is the name of the setting giving the maximum age of the local
+// gitmoji list before it is considered stale and refreshed. A zero duration
+// means the list never goes stale.
+const TTLSetting string = "cache.ttl"
+
+const fileScheme = "file://"
+
+// MetaFileSuffix is appended to the name of the cache file to give the name
+// of the file that stores the HTTP validators of the cached list.
+const MetaFileSuffix string = ".meta"
+
 // Cache is a local file cache for storing gitmoji.
 type Cache struct {
 	CacheFile string
-	gitmoji   []Gitmoji
-	url       string
-	download  func(string) ([]byte, error)
+	TTL       time.Duration
+	Custom    []CustomGitmoji
+
+	// Descriptions are translations of the gitmoji descriptions, keyed by
+	// gitmoji code. They apply to the downloaded list, and to the custom
+	// gitmoji added to it, but not to the descriptions of custom gitmoji that
+	// change a gitmoji in the list.
+	Descriptions map[string]string
+
+	gitmoji  []Gitmoji
+	sources  []string
+	source   string
+	download func(string, cacheMeta) ([]byte, cacheMeta, error)
+	refresh  chan error
+	fallback []byte
+	builtin  bool
+}
+
+// cacheMeta holds the HTTP validators returned with the cached gitmoji list,
+// so that later downloads can be made conditional.
+type cacheMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
 }
 
+// errNotModified is returned by a download when the server reports that the
+// list has not changed since it was cached.
+var errNotModified = errors.New("gitmoji list not modified")
+
 // gitmojiContainer holds a bunch of Gitmoji, for JSON decoding purposes.
 type gitmojiContainer struct {
 	Gitmoji []Gitmoji `json:"gitmojis"`
@@ -34,115 +88,384 @@ type gitmojiContainer struct {
 
 // Gitmoji is a structure with the information about a single gitmoji.
 type Gitmoji struct {
-	Emoji       string
-	Entity      string
-	Code        string
-	Description string
-	Name        string
+	Emoji       string   `json:"emoji"`
+	Entity      string   `json:"entity"`
+	Code        string   `json:"code"`
+	Description string   `json:"description"`
+	Name        string   `json:"name"`
+	Semver      string   `json:"semver"`
+	Keywords    []string `json:"keywords,omitempty"`
 }
 
-// UpdateCache checks the default URL for new gitmoji and updates the cache
-// file in local storage if it is stale.
-func UpdateCache() error {
-	homedir, err := os.UserHomeDir()
+// NewCache returns a gitmoji cache using the configured sources and local
+// storage.
+func NewCache() (Cache, error) {
+	cacheFile, _, err := DefaultCacheFile()
 
 	if err != nil {
-		return fmt.Errorf("cannot determine home directory: %v", err)
+		return Cache{}, err
 	}
 
-	cacheFile := path.Join(homedir, GitmojiDirName, GitmojiFileName)
+	sources := viper.GetStringSlice(SourcesSetting)
 
-	// Get current list
-	currentContent, err := os.ReadFile(cacheFile)
+	if len(sources) == 0 {
+		sources = []string{GitmojiURL}
+	}
 
-	if err != nil {
-		if !os.IsNotExist(err) {
-			return fmt.Errorf("unable to read local gitmoji cache: %v", err)
-		}
+	cache, err := NewCacheWithSourcesAndCacheFile(sources, cacheFile)
 
-		currentContent = []byte{}
+	if err != nil {
+		return Cache{}, err
 	}
 
-	// Fetch latest list
-	updatedContent, err := download(GitmojiURL)
+	cache.TTL = viper.GetDuration(TTLSetting)
+	cache.Descriptions = i18n.Descriptions()
+	cache.fallback = builtinGitmoji
+
+	client, err := NewHTTPClient(ClientOptionsFromConfig())
 
 	if err != nil {
-		return fmt.Errorf("cannot fetch latest gitmoji: %v", err)
+		return Cache{}, err
 	}
 
-	// Compare
-	if !bytes.Equal(currentContent, updatedContent) {
-		err = writeCache(cacheFile, updatedContent)
+	cache.SetHTTPClient(client)
 
-		if err != nil {
-			return fmt.Errorf("unable to write local gitmoji cache: %v", err)
-		}
+	err = viper.UnmarshalKey(CustomSetting, &cache.Custom)
 
-		fmt.Println("List of gitmoji updated! 🎉")
-	} else {
-		fmt.Println("List of gitmoji is already up to date. 👍")
+	if err != nil {
+		return Cache{}, fmt.Errorf("invalid custom gitmoji in configuration: %v", err)
 	}
 
-	return nil
+	return cache, nil
 }
 
-// NewCache returns a gitmoji cache using the default URL and local storage.
-func NewCache() (Cache, error) {
-	homedir, err := os.UserHomeDir()
+// DefaultCacheFile returns the path of the local gitmoji list in the user's
+// cache directory. If the list is only found in the legacy ~/.gitmoji
+// directory, that path is returned instead, along with true.
+func DefaultCacheFile() (string, bool, error) {
+	dir, err := xdg.CacheDir()
 
 	if err != nil {
-		return Cache{}, fmt.Errorf("cannot determine home directory: %v", err)
+		return "", false, err
 	}
 
-	cacheFile := path.Join(homedir, GitmojiDirName, GitmojiFileName)
-
-	return NewCacheWithURLAndCacheFile(GitmojiURL, cacheFile)
+	return xdg.Locate(dir, GitmojiFileName)
 }
 
 // NewCacheWithURLAndCacheFile returns a gitmoji cache of a custom URL and
 // local storage location. This method is intended to be used for testing only.
 func NewCacheWithURLAndCacheFile(url string, cacheFile string) (Cache, error) {
+	return NewCacheWithSourcesAndCacheFile([]string{url}, cacheFile)
+}
+
+// NewCacheWithSourcesAndCacheFile returns a gitmoji cache that downloads from
+// the first of the given sources that works, and stores the list in a custom
+// local storage location.
+func NewCacheWithSourcesAndCacheFile(sources []string, cacheFile string) (Cache, error) {
+	if len(sources) == 0 {
+		return Cache{}, fmt.Errorf("no sources given for the list of gitmoji")
+	}
+
 	return Cache{
 		CacheFile: cacheFile,
-		url:       url,
+		sources:   sources,
 		gitmoji:   nil,
-		download:  download,
+		download:  newDownloader(&http.Client{Timeout: DefaultTimeout}),
 	}, nil
 }
 
-func download(url string) ([]byte, error) {
-	fmt.Println("🌐  Fetching list of gitmoji...")
+// SetHTTPClient sets the HTTP client with which to download the gitmoji list.
+func (cache *Cache) SetHTTPClient(client *http.Client) {
+	cache.download = newDownloader(client)
+}
+
+// UpdateCache checks the configured sources for new gitmoji and updates the
+// cache file in local storage if it has changed.
+//
+// Deprecated: use NewCache and Cache.Update, which also report what changed.
+func UpdateCache() error {
+	cache, err := NewCache()
+
+	if err != nil {
+		return err
+	}
+
+	_, _, err = cache.Update()
+
+	return err
+}
+
+// Update downloads the latest gitmoji list and replaces the local file cache
+// with it if it has changed. It returns the differences between the previous
+// list and the latest one, and true if the cache was changed.
+func (cache *Cache) Update() (Diff, bool, error) {
+	previousContent, content, updated, err := cache.update()
+
+	if err != nil {
+		return Diff{}, false, err
+	}
+
+	// A missing or unreadable previous list counts as empty
+	previous, _ := parseGitmoji(previousContent)
+	latest, err := validateGitmoji(content)
+
+	if err != nil {
+		return Diff{}, false, err
+	}
+
+	return Compare(previous, latest), updated, nil
+}
+
+// update fetches the gitmoji list, sending the validators of the current cache
+// so that an unchanged list is not transferred again, unless the cache is
+// corrupted, and writes it to the cache. It returns the previous and updated content of the cache.
+func (cache *Cache) update() ([]byte, []byte, bool, error) {
+	unlock, err := lock(cache.CacheFile)
+
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	defer unlock()
+
+	meta := cacheMeta{}
+	currentContent, err := os.ReadFile(cache.CacheFile)
+
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, nil, false, fmt.Errorf("unable to read local gitmoji cache: %v", err)
+		}
+
+		currentContent = []byte{}
+	} else if _, err := validateGitmoji(currentContent); err == nil {
+		// A corrupted cache must be downloaded again, even if the list has not
+		// changed since it was cached
+		meta = readMeta(cache.metaFile())
+	}
+
+	updatedContent, updatedMeta, err := cache.downloadFromSources(meta)
+
+	if err == errNotModified {
+		return currentContent, currentContent, false, cache.touch()
+	}
+
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("cannot fetch latest gitmoji: %v", err)
+	}
+
+	_, err = validateGitmoji(updatedContent)
+
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("refusing to replace the local gitmoji cache with an invalid list (from %s): %v", cache.source, err)
+	}
+
+	updated := !bytes.Equal(currentContent, updatedContent)
+
+	if updated {
+		err = writeCache(cache.CacheFile, updatedContent)
+	} else {
+		err = cache.touch()
+	}
+
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("unable to write local gitmoji cache: %v", err)
+	}
+
+	err = writeMeta(cache.metaFile(), updatedMeta)
+
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	return currentContent, updatedContent, updated, nil
+}
+
+// downloadFromSources tries each of the cache's sources in turn, returning the
+// result of the first one that succeeds.
+func (cache *Cache) downloadFromSources(meta cacheMeta) ([]byte, cacheMeta, error) {
+	var failures []string
+
+	for _, source := range cache.sources {
+		content, updatedMeta, err := cache.download(source, meta)
+
+		if err == nil || err == errNotModified {
+			cache.source = source
+			return content, updatedMeta, err
+		}
+
+		failures = append(failures, err.Error())
+	}
+
+	if len(failures) == 0 {
+		return nil, meta, fmt.Errorf("no sources given for the list of gitmoji")
+	}
+
+	return nil, meta, errors.New(strings.Join(failures, "; "))
+}
+
+// Source returns the source from which the list of gitmoji was last
+// downloaded, or an empty string if it has not been downloaded.
+func (cache *Cache) Source() string {
+	return cache.source
+}
+
+// touch resets the age of the cache, so that it is not considered stale.
+func (cache *Cache) touch() error {
+	now := time.Now()
+	err := os.Chtimes(cache.CacheFile, now, now)
+
+	if err != nil {
+		return fmt.Errorf("unable to touch local gitmoji cache: %v", err)
+	}
+
+	return nil
+}
+
+func (cache *Cache) metaFile() string {
+	return cache.CacheFile + MetaFileSuffix
+}
+
+// newDownloader returns a function that downloads the gitmoji list using the
+// given HTTP client.
+func newDownloader(client *http.Client) func(string, cacheMeta) ([]byte, cacheMeta, error) {
+	return func(source string, meta cacheMeta) ([]byte, cacheMeta, error) {
+		return download(client, source, meta)
+	}
+}
+
+func download(client *http.Client, source string, meta cacheMeta) ([]byte, cacheMeta, error) {
+	if strings.HasPrefix(source, fileScheme) {
+		return readSource(source)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, source, nil)
+
+	if err != nil {
+		return nil, meta, fmt.Errorf("unable to download gitmoji list (from %s): %v", source, err)
+	}
+
+	// Validators are only meaningful to the server that issued them
+	if meta.URL == source {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
 
-	// #nosec G107
-	r, err := http.Get(url)
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+
+	r, err := client.Do(req)
 
 	if err != nil {
-		return nil, fmt.Errorf("unable to download gitmoji list (from %s): %v", url, err)
+		return nil, meta, fmt.Errorf("unable to download gitmoji list (from %s): %v", source, err)
 	}
 
 	defer r.Body.Close()
 
+	if r.StatusCode == http.StatusNotModified {
+		return nil, meta, errNotModified
+	}
+
 	if r.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unable to download gitmoji list (from %s): %v", url, r.Status)
+		return nil, meta, fmt.Errorf("unable to download gitmoji list (from %s): %v", source, r.Status)
 	}
 
 	body, err := io.ReadAll(r.Body)
 
 	if err != nil {
-		return nil, fmt.Errorf("unable to download gitmoji list: %v", err)
+		return nil, meta, fmt.Errorf("unable to download gitmoji list: %v", err)
+	}
+
+	updatedMeta := cacheMeta{
+		URL:          source,
+		ETag:         r.Header.Get("ETag"),
+		LastModified: r.Header.Get("Last-Modified"),
+	}
+
+	return body, updatedMeta, nil
+}
+
+// readSource reads a gitmoji list from a file:// source.
+func readSource(source string) ([]byte, cacheMeta, error) {
+	u, err := url.Parse(source)
+
+	if err != nil {
+		return nil, cacheMeta{}, fmt.Errorf("invalid gitmoji list source %s: %v", source, err)
+	}
+
+	content, err := os.ReadFile(filepath.FromSlash(u.Path))
+
+	if err != nil {
+		return nil, cacheMeta{}, fmt.Errorf("unable to read gitmoji list (from %s): %v", source, err)
+	}
+
+	return content, cacheMeta{URL: source}, nil
+}
+
+// readMeta reads the validators of the cached gitmoji list. A missing or
+// unreadable file just means the next download will be unconditional.
+func readMeta(metaFile string) cacheMeta {
+	meta := cacheMeta{}
+	content, err := os.ReadFile(metaFile)
+
+	if err != nil {
+		return meta
+	}
+
+	err = json.Unmarshal(content, &meta)
+
+	if err != nil {
+		return cacheMeta{}
+	}
+
+	return meta
+}
+
+func writeMeta(metaFile string, meta cacheMeta) error {
+	content, err := json.Marshal(meta)
+
+	if err != nil {
+		return fmt.Errorf("unable to encode gitmoji cache metadata: %v", err)
 	}
 
-	return body, nil
+	return writeCache(metaFile, content)
 }
 
+// writeCache atomically replaces the content of the cache file, by writing
+// the content to a temporary file and renaming it over the cache file, so that
+// an interrupted write never leaves a truncated cache behind.
 func writeCache(cacheFile string, content []byte) error {
-	err := os.MkdirAll(path.Dir(cacheFile), 0755)
+	dir := filepath.Dir(cacheFile)
+	err := os.MkdirAll(dir, 0755)
 
 	if err != nil {
 		return fmt.Errorf("unable to create gitmoji cache directory: %v", err)
 	}
 
-	err = os.WriteFile(cacheFile, content, 0600)
+	f, err := os.CreateTemp(dir, filepath.Base(cacheFile)+".*.tmp")
+
+	if err != nil {
+		return fmt.Errorf("unable to write gitmoji cache: %v", err)
+	}
+
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(content)
+
+	if err == nil {
+		err = f.Sync()
+	}
+
+	closeErr := f.Close()
+
+	if err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), cacheFile)
+	}
 
 	if err != nil {
 		return fmt.Errorf("unable to write gitmoji cache: %v", err)
@@ -152,7 +475,15 @@ func writeCache(cacheFile string, content []byte) error {
 }
 
 // GetGitmoji gets the gitmoji list from a local file cache if available;
-// otherwise, downloads the latest gitmoji list from github.com.
+// otherwise, downloads the latest gitmoji list from github.com. If that fails
+// too, the built-in snapshot of the list is used, if the cache has one. The
+// cache's custom gitmoji and the built-in search keywords are merged into the
+// result.
+//
+// If the local file cache is older than the cache's TTL, the cached list is
+// returned immediately and a fresh list is downloaded in the background; call
+// WaitForRefresh to wait for the download to finish. If the local file cache
+// is corrupted, the list is downloaded again.
 func (cache *Cache) GetGitmoji() ([]Gitmoji, error) {
 	if cache.gitmoji != nil {
 		return cache.gitmoji, nil
@@ -160,32 +491,162 @@ func (cache *Cache) GetGitmoji() ([]Gitmoji, error) {
 
 	content, err := os.ReadFile(cache.CacheFile)
 
-	if err != nil {
-		if os.IsNotExist(err) {
-			content, err = cache.download(cache.url)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to read gitmoji cache: %v", err)
+	}
 
-			if err != nil {
-				return nil, err
+	if err == nil {
+		gitmoji, err := validateGitmoji(content)
+
+		if err == nil {
+			if cache.isStale() {
+				cache.startRefresh()
 			}
 
-			err = writeCache(cache.CacheFile, content)
+			cache.gitmoji = cache.merge(gitmoji)
 
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			return nil, fmt.Errorf("unable to read gitmoji cache: %v", err)
+			return cache.gitmoji, nil
 		}
+
+		// Recover by downloading the list again, unconditionally
+		fmt.Fprintf(os.Stderr, "⚠️  The gitmoji list in %v is corrupted (%v), so downloading it again.\n", cache.CacheFile, err)
+		os.Remove(cache.metaFile())
 	}
 
-	container := gitmojiContainer{}
-	err = json.Unmarshal(content, &container)
+	fmt.Fprintln(os.Stderr, "🌐  Fetching list of gitmoji...")
+	_, content, _, err = cache.update()
+
+	if err != nil {
+		if cache.fallback == nil {
+			return nil, err
+		}
+
+		content = cache.fallback
+		cache.builtin = true
+	}
+
+	gitmoji, err := validateGitmoji(content)
 
 	if err != nil {
 		return nil, fmt.Errorf("cannot process gitmoji list; perhaps the file %v is corrupted? Underlying error: %v", cache.CacheFile, err)
 	}
 
-	cache.gitmoji = container.Gitmoji
+	cache.gitmoji = cache.merge(gitmoji)
 
 	return cache.gitmoji, nil
 }
+
+// merge returns the downloaded gitmoji list, translated and with the built-in
+// keywords added, with the cache's custom gitmoji merged into it. The custom
+// gitmoji added to the list are translated too.
+func (cache *Cache) merge(list []Gitmoji) []Gitmoji {
+	listed := make(map[string]bool, len(list))
+
+	for _, g := range list {
+		listed[g.Code] = true
+	}
+
+	custom := make([]CustomGitmoji, len(cache.Custom))
+
+	for i, c := range cache.Custom {
+		if !listed[c.Code] {
+			c.Gitmoji = translate([]Gitmoji{c.Gitmoji}, cache.Descriptions)[0]
+		}
+
+		custom[i] = c
+	}
+
+	return applyCustom(addKeywords(translate(list, cache.Descriptions)), custom)
+}
+
+// UsingBuiltin reports whether the list returned by GetGitmoji is the snapshot
+// compiled into this program, because the local cache was missing or corrupted
+// and the latest list could not be downloaded.
+func (cache *Cache) UsingBuiltin() bool {
+	return cache.builtin
+}
+
+// WaitForRefresh waits for the background refresh started by GetGitmoji, if
+// any, to finish. If the refresh failed, the error is returned and the stale
+// list remains in use.
+func (cache *Cache) WaitForRefresh() error {
+	if cache.refresh == nil {
+		return nil
+	}
+
+	err := <-cache.refresh
+	cache.refresh = nil
+
+	if err != nil {
+		return fmt.Errorf("unable to refresh stale gitmoji list, so using the cached copy: %v", err)
+	}
+
+	return nil
+}
+
+func (cache *Cache) isStale() bool {
+	if cache.TTL <= 0 {
+		return false
+	}
+
+	info, err := os.Stat(cache.CacheFile)
+
+	if err != nil {
+		return false
+	}
+
+	return time.Since(info.ModTime()) > cache.TTL
+}
+
+func (cache *Cache) startRefresh() {
+	cache.refresh = make(chan error, 1)
+
+	go func() {
+		_, _, _, err := cache.update()
+		cache.refresh <- err
+	}()
+}
+
+// validateGitmoji parses a gitmoji list, checking that it is not empty and that
+// every gitmoji has an emoji, a code and a description.
+func validateGitmoji(content []byte) ([]Gitmoji, error) {
+	gitmoji, err := parseGitmoji(content)
+
+	if err != nil {
+		return nil, fmt.Errorf("not a gitmoji list: %v", err)
+	}
+
+	if len(gitmoji) == 0 {
+		return nil, fmt.Errorf("the gitmoji list is empty")
+	}
+
+	for i, g := range gitmoji {
+		var missing string
+
+		switch {
+		case g.Emoji == "":
+			missing = "emoji"
+		case g.Code == "":
+			missing = "code"
+		case g.Description == "":
+			missing = "description"
+		default:
+			continue
+		}
+
+		return nil, fmt.Errorf("gitmoji number %d (%s%s) has no %s", i+1, g.Emoji, g.Code, missing)
+	}
+
+	return gitmoji, nil
+}
+
+func parseGitmoji(content []byte) ([]Gitmoji, error) {
+	container := gitmojiContainer{}
+	err := json.Unmarshal(content, &container)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return container.Gitmoji, nil
+}
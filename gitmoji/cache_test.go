@@ -1,14 +1,18 @@
 package gitmoji
 
 import (
+	"bufio"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
-	"path"
+	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestInvalidJSON(t *testing.T) {
@@ -33,10 +37,11 @@ func TestInvalidJSON(t *testing.T) {
 
 	cache := Cache{
 		CacheFile: f.Name(),
+		sources:   []string{GitmojiURL},
 		gitmoji:   nil,
 
-		download: func(string) ([]byte, error) {
-			return []byte("This is also not valid JSON"), nil
+		download: func(string, cacheMeta) ([]byte, cacheMeta, error) {
+			return []byte("This is also not valid JSON"), cacheMeta{}, nil
 		},
 	}
 
@@ -61,6 +66,206 @@ func TestInvalidJSON(t *testing.T) {
 	}
 }
 
+func TestCorruptedCacheRecovery(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), GitmojiFileName)
+	content := `{"gitmojis": [{"emoji": "🎨", "code": ":art:", "description": "Improve structure / format of the code."}]}`
+
+	// A truncated cache file, as left behind by an interrupted write
+	err := os.WriteFile(cacheFile, []byte(content[:20]), 0600)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := Cache{
+		CacheFile: cacheFile,
+		sources:   []string{GitmojiURL},
+
+		download: func(string, cacheMeta) ([]byte, cacheMeta, error) {
+			return []byte(content), cacheMeta{}, nil
+		},
+	}
+
+	gitmoji, err := cache.GetGitmoji()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gitmoji) != 1 {
+		t.Fatal("Didn't read gitmoji correctly; read this instead: ", gitmoji)
+	}
+
+	current, err := os.ReadFile(cacheFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(current) != content {
+		t.Fatal("Expected corrupted cache file to be replaced, got this: ", string(current))
+	}
+}
+
+func TestWriteCache(t *testing.T) {
+	dir := t.TempDir()
+	cacheFile := filepath.Join(dir, "gitmoji", GitmojiFileName)
+
+	for _, content := range []string{"first", "second"} {
+		err := writeCache(cacheFile, []byte(content))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		current, err := os.ReadFile(cacheFile)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(current) != content {
+			t.Fatalf("Expected cache file to contain %q, got %q", content, string(current))
+		}
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(cacheFile))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatal("Expected only the cache file, got this: ", entries)
+	}
+}
+
+func TestLock(t *testing.T) {
+	file := filepath.Join(t.TempDir(), GitmojiFileName)
+
+	defer func(timeout time.Duration) {
+		lockTimeout = timeout
+	}(lockTimeout)
+
+	lockTimeout = 300 * time.Millisecond
+	unlock, err := lock(file)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The lock is held, so a second attempt times out
+	_, err = lock(file)
+
+	if err == nil {
+		t.Fatal("Expected timeout waiting for lock.")
+	}
+
+	unlock()
+
+	// The lock is released, so it can be taken again, even though the lock
+	// file is still there
+	unlock, err = lock(file)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unlock()
+}
+
+func TestLockExcludesWaiters(t *testing.T) {
+	file := filepath.Join(t.TempDir(), GitmojiFileName)
+	holders := int32(0)
+	errs := make(chan error, 10)
+
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			unlock, err := lock(file)
+
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			if atomic.AddInt32(&holders, 1) != 1 {
+				err = fmt.Errorf("the lock is held more than once")
+			}
+
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&holders, -1)
+			unlock()
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLockReleasedOnExit(t *testing.T) {
+	if file := os.Getenv("GITMOJI_TEST_LOCK"); file != "" {
+		// Run as a separate process that takes the lock and waits to be killed
+		if _, err := lock(file); err != nil {
+			t.Fatal(err)
+		}
+
+		fmt.Println("locked")
+		time.Sleep(time.Minute)
+
+		return
+	}
+
+	file := filepath.Join(t.TempDir(), GitmojiFileName)
+
+	defer func(timeout time.Duration) {
+		lockTimeout = timeout
+	}(lockTimeout)
+
+	lockTimeout = 300 * time.Millisecond
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestLockReleasedOnExit$")
+	cmd.Env = append(os.Environ(), "GITMOJI_TEST_LOCK="+file)
+	out, err := cmd.StdoutPipe()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	line, err := bufio.NewReader(out).ReadString('\n')
+
+	if err != nil || line != "locked\n" {
+		t.Fatalf("Expected the other process to take the lock; got %q, %v", line, err)
+	}
+
+	// While the other process holds the lock, it can't be taken
+	if _, err := lock(file); err == nil {
+		t.Fatal("Expected timeout waiting for lock.")
+	}
+
+	// The lock is released when the process is killed
+	if err := cmd.Process.Kill(); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = cmd.Wait()
+	unlock, err := lock(file)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unlock()
+}
+
 func TestEmptyJSON(t *testing.T) {
 	f, err := os.CreateTemp("", "gitmoji")
 	defer os.Remove(f.Name())
@@ -83,23 +288,107 @@ func TestEmptyJSON(t *testing.T) {
 
 	cache := Cache{
 		CacheFile: f.Name(),
+		sources:   []string{GitmojiURL},
 		gitmoji:   nil,
 
-		download: func(string) ([]byte, error) {
-			t.Fatal("This should not be called")
-			return nil, nil
+		download: func(string, cacheMeta) ([]byte, cacheMeta, error) {
+			return []byte(`{"gitmojis": []}`), cacheMeta{}, nil
 		},
 	}
 
-	// Load from cache file
+	// An empty list is invalid, whether cached or downloaded
 	gitmoji, err := cache.GetGitmoji()
 
+	if err == nil {
+		t.Fatal("Expected error for empty gitmoji list, got this: ", gitmoji)
+	}
+
+	current, err := os.ReadFile(f.Name())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(current) != "{}" {
+		t.Fatal("Expected cache file to be left alone, got this: ", string(current))
+	}
+}
+
+func TestValidateGitmoji(t *testing.T) {
+	invalid := map[string]string{
+		"captive portal":      "<html><body>Please log in to the Wi-Fi</body></html>",
+		"empty object":        "{}",
+		"empty list":          `{"gitmojis": []}`,
+		"wrong type":          `{"gitmojis": "🎨"}`,
+		"missing emoji":       `{"gitmojis": [{"code": ":art:", "description": "Improve structure / format of the code."}]}`,
+		"missing code":        `{"gitmojis": [{"emoji": "🎨", "description": "Improve structure / format of the code."}]}`,
+		"missing description": `{"gitmojis": [{"emoji": "🎨", "code": ":art:"}]}`,
+	}
+
+	for name, content := range invalid {
+		_, err := validateGitmoji([]byte(content))
+
+		if err == nil {
+			t.Errorf("Expected %s to be invalid.", name)
+		}
+	}
+
+	gitmoji, err := validateGitmoji(builtinGitmoji)
+
 	if err != nil {
-		t.Fatal("Expected empty gitmoji list, got error: ", err)
+		t.Fatal("Expected built-in gitmoji list to be valid, got error: ", err)
 	}
 
-	if len(gitmoji) != 0 {
-		t.Fatal("Expected empty gitmoji list, got this: ", gitmoji)
+	if len(gitmoji) == 0 {
+		t.Fatal("Expected to get some built-in gitmoji, but got nothing 😿")
+	}
+}
+
+func TestInvalidUpdateRejected(t *testing.T) {
+	content := `{"gitmojis": [{"emoji": "🎨", "code": ":art:", "description": "Improve structure / format of the code."}]}`
+	cacheFile := writeStaleCacheFile(t, content)
+	defer os.Remove(cacheFile)
+
+	cache := Cache{
+		CacheFile: cacheFile,
+		sources:   []string{GitmojiURL},
+
+		download: func(string, cacheMeta) ([]byte, cacheMeta, error) {
+			return []byte("<html><body>Please log in to the Wi-Fi</body></html>"), cacheMeta{}, nil
+		},
+	}
+
+	_, _, err := cache.Update()
+
+	if err == nil {
+		t.Fatal("Expected error updating with an invalid list.")
+	}
+
+	current, err := os.ReadFile(cacheFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(current) != content {
+		t.Fatal("Expected cache file to be left alone, got this: ", string(current))
+	}
+}
+
+func TestSemver(t *testing.T) {
+	gitmoji, err := parseGitmoji([]byte(`{
+		"gitmojis": [
+		  {"emoji": "🎨", "code": ":art:", "semver": null},
+		  {"emoji": "✨", "code": ":sparkles:", "semver": "minor"}
+		]
+	}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gitmoji[0].Semver != "" || gitmoji[1].Semver != "minor" {
+		t.Fatal("Didn't read semver correctly; read this instead: ", gitmoji)
 	}
 }
 
@@ -115,7 +404,7 @@ func TestUnreadableCacheFile(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, err = f.Write([]byte("{}"))
+	_, err = f.Write([]byte(`{"gitmojis": [{"emoji": "🎨", "code": ":art:", "description": "Improve structure / format of the code."}]}`))
 
 	if err != nil {
 		t.Fatal(err)
@@ -129,11 +418,12 @@ func TestUnreadableCacheFile(t *testing.T) {
 
 	cache := Cache{
 		CacheFile: f.Name(),
+		sources:   []string{GitmojiURL},
 		gitmoji:   nil,
 
-		download: func(string) ([]byte, error) {
+		download: func(string, cacheMeta) ([]byte, cacheMeta, error) {
 			t.Fatal("This should not be called")
-			return nil, nil
+			return nil, cacheMeta{}, nil
 		},
 	}
 
@@ -152,13 +442,14 @@ func TestUnreadableCacheFile(t *testing.T) {
 }
 
 func TestErrorFetchingData(t *testing.T) {
-	cacheFile := path.Join(os.TempDir(), "gitmoji-file-not-found.json")
+	cacheFile := filepath.Join(os.TempDir(), "gitmoji-file-not-found.json")
 	cache := Cache{
 		CacheFile: cacheFile,
+		sources:   []string{GitmojiURL},
 		gitmoji:   nil,
 
-		download: func(string) ([]byte, error) {
-			return nil, fmt.Errorf("trigger an error")
+		download: func(string, cacheMeta) ([]byte, cacheMeta, error) {
+			return nil, cacheMeta{}, fmt.Errorf("trigger an error")
 		},
 	}
 
@@ -170,8 +461,225 @@ func TestErrorFetchingData(t *testing.T) {
 	}
 }
 
+func TestStaleCacheRefresh(t *testing.T) {
+	cacheFile := writeStaleCacheFile(t, `{"gitmojis": [{"emoji": "🎨", "code": ":art:", "description": "Improve structure / format of the code."}]}`)
+	defer os.Remove(cacheFile)
+	defer os.Remove(cacheFile + MetaFileSuffix)
+
+	cache := Cache{
+		CacheFile: cacheFile,
+		sources:   []string{GitmojiURL},
+		TTL:       time.Hour,
+
+		download: func(string, cacheMeta) ([]byte, cacheMeta, error) {
+			return []byte(`{"gitmojis": [{"emoji": "🎨", "code": ":art:", "description": "Improve structure / format of the code."}, {"emoji": "⚡️", "code": ":zap:", "description": "Improve performance."}]}`), cacheMeta{}, nil
+		},
+	}
+
+	// The stale list is returned without waiting for the download
+	gitmoji, err := cache.GetGitmoji()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gitmoji) != 1 {
+		t.Fatal("Expected the stale gitmoji list, got this: ", gitmoji)
+	}
+
+	err = cache.WaitForRefresh()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The next cache to read the file gets the refreshed list
+	refreshed := Cache{CacheFile: cacheFile}
+	gitmoji, err = refreshed.GetGitmoji()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gitmoji) != 2 {
+		t.Fatal("Expected the refreshed gitmoji list, got this: ", gitmoji)
+	}
+}
+
+func TestStaleCacheRefreshFailure(t *testing.T) {
+	content := `{"gitmojis": [{"emoji": "🎨", "code": ":art:", "description": "Improve structure / format of the code."}]}`
+	cacheFile := writeStaleCacheFile(t, content)
+	defer os.Remove(cacheFile)
+
+	cache := Cache{
+		CacheFile: cacheFile,
+		sources:   []string{GitmojiURL},
+		TTL:       time.Hour,
+
+		download: func(string, cacheMeta) ([]byte, cacheMeta, error) {
+			return nil, cacheMeta{}, fmt.Errorf("trigger an error")
+		},
+	}
+
+	gitmoji, err := cache.GetGitmoji()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gitmoji) != 1 {
+		t.Fatal("Expected the stale gitmoji list, got this: ", gitmoji)
+	}
+
+	err = cache.WaitForRefresh()
+
+	if err == nil {
+		t.Fatal("Expected error refreshing stale cache.")
+	}
+
+	current, err := os.ReadFile(cacheFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(current) != content {
+		t.Fatal("Expected stale cache file to be left alone, got this: ", string(current))
+	}
+}
+
+func TestFreshCacheNotRefreshed(t *testing.T) {
+	cacheFile := writeStaleCacheFile(t, `{"gitmojis": [{"emoji": "🎨", "code": ":art:", "description": "Improve structure / format of the code."}]}`)
+	defer os.Remove(cacheFile)
+
+	cache := Cache{
+		CacheFile: cacheFile,
+		sources:   []string{GitmojiURL},
+		TTL:       30 * 24 * time.Hour,
+
+		download: func(string, cacheMeta) ([]byte, cacheMeta, error) {
+			t.Fatal("This should not be called")
+			return nil, cacheMeta{}, nil
+		},
+	}
+
+	_, err := cache.GetGitmoji()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = cache.WaitForRefresh()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeStaleCacheFile writes a temporary cache file that was last modified
+// two days ago.
+func writeStaleCacheFile(t *testing.T, content string) string {
+	f, err := os.CreateTemp("", "gitmoji")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = f.Write([]byte(content))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = f.Close()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modified := time.Now().Add(-48 * time.Hour)
+	err = os.Chtimes(f.Name(), modified, modified)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return f.Name()
+}
+
+func TestBuiltinFallback(t *testing.T) {
+	cache := Cache{
+		CacheFile: filepath.Join(t.TempDir(), GitmojiFileName),
+		sources:   []string{GitmojiURL},
+		fallback:  builtinGitmoji,
+
+		download: func(string, cacheMeta) ([]byte, cacheMeta, error) {
+			return nil, cacheMeta{}, fmt.Errorf("trigger an error")
+		},
+	}
+
+	gitmoji, err := cache.GetGitmoji()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cache.UsingBuiltin() {
+		t.Fatal("Expected the built-in gitmoji list to be in use.")
+	}
+
+	if len(gitmoji) == 0 {
+		t.Fatal("Expected to get some built-in gitmoji, but got nothing 😿")
+	}
+
+	for _, g := range gitmoji {
+		if g.Emoji == "" || g.Code == "" || g.Description == "" {
+			t.Fatal("Built-in gitmoji is incomplete: ", g)
+		}
+	}
+}
+
+func TestFallbackSources(t *testing.T) {
+	dir := t.TempDir()
+	cacheFile := filepath.Join(dir, GitmojiFileName)
+	mirrorFile := filepath.Join(dir, "mirror.json")
+
+	err := os.WriteFile(mirrorFile, []byte(`{"gitmojis": [{"emoji": "🎨", "code": ":art:", "description": "Improve structure / format of the code."}]}`), 0600)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Testing HTTP Server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	mirror := "file://" + filepath.ToSlash(mirrorFile)
+	cache, err := NewCacheWithSourcesAndCacheFile([]string{server.URL, mirror}, cacheFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gitmoji, err := cache.GetGitmoji()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gitmoji) != 1 {
+		t.Fatal("Didn't read gitmoji correctly; read this instead: ", gitmoji)
+	}
+
+	if cache.Source() != mirror {
+		t.Fatal("Expected gitmoji to come from the mirror, but got them from: ", cache.Source())
+	}
+}
+
 func TestLoadFromURL(t *testing.T) {
-	cacheFile := path.Join(os.TempDir(), "gitmoji-temp-file.json")
+	cacheFile := filepath.Join(os.TempDir(), "gitmoji-temp-file.json")
 
 	// Testing HTTP Server
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
@@ -218,10 +726,112 @@ func TestLoadFromURL(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+
+	err = os.Remove(cacheFile + MetaFileSuffix)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConditionalUpdate(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), GitmojiFileName)
+	content := `{"gitmojis": [{"emoji": "🎨", "code": ":art:", "description": "Improve structure / format of the code."}]}`
+	lastModified := "Mon, 02 Jan 2006 15:04:05 GMT"
+	downloads := 0
+
+	// Testing HTTP Server
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") == lastModified {
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		downloads++
+		rw.Header().Set("ETag", `"v1"`)
+		rw.Header().Set("Last-Modified", lastModified)
+		_, err := rw.Write([]byte(content))
+
+		if err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	cache, err := NewCacheWithURLAndCacheFile(server.URL, cacheFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First update downloads the list
+	_, updated, err := cache.Update()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !updated || downloads != 1 {
+		t.Fatalf("Expected the list to be downloaded; updated: %v, downloads: %d", updated, downloads)
+	}
+
+	meta := readMeta(cacheFile + MetaFileSuffix)
+
+	if meta.ETag != `"v1"` || meta.LastModified != lastModified {
+		t.Fatal("Expected validators to be stored, got this: ", meta)
+	}
+
+	// Second update is answered with 304 Not Modified
+	_, updated, err = cache.Update()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated || downloads != 1 {
+		t.Fatalf("Expected the list not to be downloaded again; updated: %v, downloads: %d", updated, downloads)
+	}
+
+	current, err := os.ReadFile(cacheFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(current) != content {
+		t.Fatal("Expected cache file to be unchanged, got this: ", string(current))
+	}
+
+	// A corrupted cache is downloaded again, even though the list is unchanged
+	err = os.WriteFile(cacheFile, []byte(content[:20]), 0600)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, updated, err = cache.Update()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !updated || downloads != 2 {
+		t.Fatalf("Expected the corrupted list to be downloaded again; updated: %v, downloads: %d", updated, downloads)
+	}
+
+	current, err = os.ReadFile(cacheFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(current) != content {
+		t.Fatal("Expected corrupted cache file to be replaced, got this: ", string(current))
+	}
 }
 
 func TestLoad404(t *testing.T) {
-	cacheFile := path.Join(os.TempDir(), "gitmoji-temp-file.json")
+	cacheFile := filepath.Join(os.TempDir(), "gitmoji-temp-file.json")
 
 	err := os.Remove(cacheFile)
 
@@ -263,6 +873,10 @@ func TestLoad404(t *testing.T) {
 }
 
 func TestIntegrationDownload(t *testing.T) {
+	// Don't touch the user's own cache, whether in the XDG or legacy directory
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
 	cache, err := NewCache()
 
 	if err != nil {
@@ -275,6 +889,10 @@ func TestIntegrationDownload(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	if cache.UsingBuiltin() {
+		t.Fatal("Expected to download the gitmoji, but the built-in list was used")
+	}
+
 	if len(cache.gitmoji) == 0 {
 		t.Fatal("Expected to get some gitmoji, but got nothing 😿")
 	}
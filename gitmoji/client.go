@@ -0,0 +1,110 @@
+package gitmoji
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// TimeoutSetting is the name of the setting giving the maximum time to wait
+// for the gitmoji list to download.
+const TimeoutSetting string = "cache.timeout"
+
+// CAFileSetting is the name of the setting giving the path of a PEM file of
+// additional certificate authorities to trust when downloading the gitmoji
+// list, e.g. that of a TLS-intercepting corporate proxy.
+const CAFileSetting string = "cache.cafile"
+
+// ProxySetting is the name of the setting giving the URL of the proxy through
+// which to download the gitmoji list. When unset, the proxy is taken from the
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.
+const ProxySetting string = "cache.proxy"
+
+// DefaultTimeout is the maximum time to wait for the gitmoji list to download
+// when no timeout is configured.
+const DefaultTimeout = 10 * time.Second
+
+// ClientOptions configures the HTTP client used to download the gitmoji list.
+type ClientOptions struct {
+	Timeout time.Duration
+	CAFile  string
+	Proxy   string
+}
+
+// ClientOptionsFromConfig returns the HTTP client options given by the
+// configuration.
+func ClientOptionsFromConfig() ClientOptions {
+	return ClientOptions{
+		Timeout: viper.GetDuration(TimeoutSetting),
+		CAFile:  viper.GetString(CAFileSetting),
+		Proxy:   viper.GetString(ProxySetting),
+	}
+}
+
+// NewHTTPClient returns an HTTP client for downloading the gitmoji list.
+func NewHTTPClient(options ClientOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if options.Proxy != "" {
+		proxy, err := url.Parse(options.Proxy)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %s: %v", options.Proxy, err)
+		}
+
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if options.CAFile != "" {
+		pool, err := loadCAFile(options.CAFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    pool,
+		}
+	}
+
+	timeout := options.Timeout
+
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}, nil
+}
+
+// loadCAFile returns the system's certificate pool with the certificates in
+// the given PEM file added to it.
+func loadCAFile(caFile string) (*x509.CertPool, error) {
+	// #nosec G304
+	pem, err := os.ReadFile(caFile)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read CA file: %v", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA file %s", caFile)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,156 @@
+package gitmoji
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testGitmojiList = `{"gitmojis": [{"emoji": "🎨", "code": ":art:", "description": "Improve structure / format of the code."}]}`
+
+func TestCustomCA(t *testing.T) {
+	dir := t.TempDir()
+
+	// Testing HTTPS Server, with a certificate from an untrusted CA
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		_, err := rw.Write([]byte(testGitmojiList))
+
+		if err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	// Without the CA, the download fails
+	cache, err := NewCacheWithURLAndCacheFile(server.URL, filepath.Join(dir, "untrusted.json"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = cache.Update()
+
+	if err == nil {
+		t.Fatal("Expected error downloading from server with untrusted certificate.")
+	}
+
+	// With the CA, it succeeds
+	caFile := filepath.Join(dir, "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	err = os.WriteFile(caFile, ca, 0600)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewHTTPClient(ClientOptions{CAFile: caFile})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err = NewCacheWithURLAndCacheFile(server.URL, filepath.Join(dir, "trusted.json"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.SetHTTPClient(client)
+	_, updated, err := cache.Update()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !updated {
+		t.Fatal("Expected gitmoji list to be downloaded.")
+	}
+}
+
+func TestInvalidCAFile(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	err := os.WriteFile(caFile, []byte("not a certificate"), 0600)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewHTTPClient(ClientOptions{CAFile: caFile})
+
+	if err == nil {
+		t.Fatal("Expected error loading invalid CA file.")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+
+	// Testing HTTP Server, which hangs
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewHTTPClient(ClientOptions{Timeout: 100 * time.Millisecond})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := NewCacheWithURLAndCacheFile(server.URL, filepath.Join(t.TempDir(), GitmojiFileName))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.SetHTTPClient(client)
+	_, _, err = cache.Update()
+
+	if err == nil {
+		t.Fatal("Expected download to time out.")
+	}
+}
+
+func TestProxy(t *testing.T) {
+	var proxied string
+
+	// Testing HTTP proxy, which answers every request itself
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		_, err := rw.Write([]byte(testGitmojiList))
+
+		if err != nil {
+			t.Error(err)
+		}
+	}))
+	defer proxy.Close()
+
+	client, err := NewHTTPClient(ClientOptions{Proxy: proxy.URL})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := "http://gitmoji.invalid/gitmojis.json"
+	cache, err := NewCacheWithURLAndCacheFile(source, filepath.Join(t.TempDir(), GitmojiFileName))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.SetHTTPClient(client)
+	_, _, err = cache.Update()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if proxied != source {
+		t.Fatal("Expected request to go through the proxy, but the proxy got: ", proxied)
+	}
+}
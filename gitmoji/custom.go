@@ -0,0 +1,110 @@
+package gitmoji
+
+// CustomSetting is the name of the setting listing custom gitmoji.
+const CustomSetting string = "gitmojis"
+
+// CustomGitmoji is a gitmoji defined in configuration. If its code matches
+// that of a gitmoji in the downloaded list, the non-empty fields replace those
+// of the downloaded gitmoji (except for keywords, which are added to those of
+// the downloaded gitmoji), or the gitmoji is removed if Hidden is set;
+// otherwise, it is added to the end of the list.
+type CustomGitmoji struct {
+	Gitmoji `mapstructure:",squash"`
+	Hidden  bool
+}
+
+// applyCustom returns the gitmoji list with the custom gitmoji merged into it.
+// When several custom gitmoji have the same code, the last one wins.
+func applyCustom(list []Gitmoji, custom []CustomGitmoji) []Gitmoji {
+	if len(custom) == 0 {
+		return list
+	}
+
+	byCode := make(map[string]CustomGitmoji, len(custom))
+
+	for _, c := range custom {
+		byCode[c.Code] = c
+	}
+
+	result := make([]Gitmoji, 0, len(list)+len(custom))
+	seen := make(map[string]bool, len(list)+len(custom))
+
+	for _, g := range list {
+		seen[g.Code] = true
+		c, ok := byCode[g.Code]
+
+		if !ok {
+			result = append(result, g)
+			continue
+		}
+
+		if c.Hidden {
+			continue
+		}
+
+		result = append(result, override(g, c.Gitmoji))
+	}
+
+	for _, c := range custom {
+		if seen[c.Code] {
+			continue
+		}
+
+		seen[c.Code] = true
+		c = byCode[c.Code]
+
+		if !c.Hidden {
+			result = append(result, c.Gitmoji)
+		}
+	}
+
+	return result
+}
+
+// override returns the gitmoji g with the non-empty fields of o replacing
+// those of g.
+func override(g Gitmoji, o Gitmoji) Gitmoji {
+	if o.Emoji != "" {
+		g.Emoji = o.Emoji
+	}
+
+	if o.Entity != "" {
+		g.Entity = o.Entity
+	}
+
+	if o.Description != "" {
+		g.Description = o.Description
+	}
+
+	if o.Name != "" {
+		g.Name = o.Name
+	}
+
+	if o.Semver != "" {
+		g.Semver = o.Semver
+	}
+
+	g.Keywords = appendMissing(g.Keywords, o.Keywords)
+
+	return g
+}
+
+// translate returns the gitmoji list with the descriptions replaced by their
+// translations, keyed by gitmoji code.
+func translate(list []Gitmoji, descriptions map[string]string) []Gitmoji {
+	if len(descriptions) == 0 {
+		return list
+	}
+
+	translated := make([]Gitmoji, len(list))
+
+	for i, g := range list {
+		if description, ok := descriptions[g.Code]; ok {
+			g.Description = description
+		}
+
+		translated[i] = g
+	}
+
+	return translated
+}
@@ -0,0 +1,107 @@
+package gitmoji
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jamesdobson/gogitmoji/i18n"
+)
+
+func TestApplyCustom(t *testing.T) {
+	assert := assert.New(t)
+
+	upstream := []Gitmoji{
+		{Emoji: "🎨", Code: ":art:", Description: "Improve structure / format of the code."},
+		{Emoji: "🐛", Code: ":bug:", Description: "Fix a bug."},
+		{Emoji: "💩", Code: ":poop:", Description: "Write bad code that needs to be improved."},
+	}
+
+	custom := []CustomGitmoji{
+		{Gitmoji: Gitmoji{Emoji: "🧯", Code: ":fire_extinguisher:", Description: "Quarantine a flaky test."}},
+		{Gitmoji: Gitmoji{Code: ":bug:", Description: "Fix a bug (add the ticket number!)."}},
+		{Gitmoji: Gitmoji{Code: ":poop:"}, Hidden: true},
+	}
+
+	assert.Equal([]Gitmoji{
+		{Emoji: "🎨", Code: ":art:", Description: "Improve structure / format of the code."},
+		{Emoji: "🐛", Code: ":bug:", Description: "Fix a bug (add the ticket number!)."},
+		{Emoji: "🧯", Code: ":fire_extinguisher:", Description: "Quarantine a flaky test."},
+	}, applyCustom(upstream, custom))
+
+	assert.Equal(upstream, applyCustom(upstream, nil))
+}
+
+func TestApplyCustomLastWins(t *testing.T) {
+	assert := assert.New(t)
+
+	custom := []CustomGitmoji{
+		{Gitmoji: Gitmoji{Emoji: "🧯", Code: ":fire_extinguisher:", Description: "From user config."}},
+		{Gitmoji: Gitmoji{Emoji: "🧯", Code: ":fire_extinguisher:", Description: "From repository config."}},
+		{Gitmoji: Gitmoji{Emoji: "🛃", Code: ":customs:", Description: "From user config."}},
+		{Gitmoji: Gitmoji{Code: ":customs:"}, Hidden: true},
+	}
+
+	assert.Equal([]Gitmoji{
+		{Emoji: "🧯", Code: ":fire_extinguisher:", Description: "From repository config."},
+	}, applyCustom(nil, custom))
+}
+
+func TestCustomGitmojiTranslated(t *testing.T) {
+	assert := assert.New(t)
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "pt_BR.UTF-8")
+
+	// The user translates a custom gitmoji that is added to the list
+	localesDir := filepath.Join(configHome, "gitmoji", i18n.LocalesDirName)
+	assert.NoError(os.MkdirAll(localesDir, 0755))
+	assert.NoError(os.WriteFile(filepath.Join(localesDir, "pt.yaml"), []byte(`
+gitmojis:
+  ":fire_extinguisher:": "Colocar um teste instável em quarentena."
+`), 0600))
+
+	assert.NoError(i18n.Load())
+	defer func() {
+		t.Setenv("LANG", "")
+		assert.NoError(i18n.Load())
+	}()
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "gitmojis.json")
+	assert.NoError(os.WriteFile(source, []byte(`{"gitmojis": [
+		{"emoji": "🎨", "code": ":art:", "description": "Improve structure / format of the code."},
+		{"emoji": "🐛", "code": ":bug:", "description": "Fix a bug."}
+	]}`), 0600))
+
+	cache, err := NewCacheWithSourcesAndCacheFile([]string{fileScheme + filepath.ToSlash(source)}, filepath.Join(dir, GitmojiFileName))
+	assert.NoError(err)
+
+	cache.Descriptions = i18n.Descriptions()
+	cache.Custom = []CustomGitmoji{
+		{Gitmoji: Gitmoji{Emoji: "🧯", Code: ":fire_extinguisher:", Description: "Quarantine a flaky test."}},
+		{Gitmoji: Gitmoji{Code: ":bug:", Description: "Fix a bug (add the ticket number!)."}},
+	}
+
+	list, err := cache.GetGitmoji()
+	assert.NoError(err)
+
+	descriptions := map[string]string{}
+
+	for _, g := range list {
+		descriptions[g.Code] = g.Description
+	}
+
+	// The team's description of :bug: is not replaced by the built-in
+	// translation of the upstream one
+	assert.Equal(map[string]string{
+		":art:":               "Melhorar a estrutura / o formato do código.",
+		":bug:":               "Fix a bug (add the ticket number!).",
+		":fire_extinguisher:": "Colocar um teste instável em quarentena.",
+	}, descriptions)
+}
@@ -0,0 +1,100 @@
+package gitmoji
+
+import (
+	"strings"
+)
+
+// Diff describes the differences between two lists of gitmoji.
+type Diff struct {
+	Added   []Gitmoji `json:"added"`
+	Removed []Gitmoji `json:"removed"`
+	Changed []Change  `json:"changed"`
+	Renamed []Change  `json:"renamed"`
+}
+
+// Change is a gitmoji as it appears in both lists of a Diff. In Diff.Changed,
+// the gitmoji have the same code but different descriptions; in Diff.Renamed,
+// they have the same emoji or name but different codes.
+type Change struct {
+	Old Gitmoji `json:"old"`
+	New Gitmoji `json:"new"`
+}
+
+// IsEmpty reports whether there are no differences.
+func (d Diff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 && len(d.Renamed) == 0
+}
+
+// Compare returns the differences between an old and a new list of gitmoji.
+func Compare(oldList []Gitmoji, newList []Gitmoji) Diff {
+	diff := Diff{
+		Added:   []Gitmoji{},
+		Removed: []Gitmoji{},
+		Changed: []Change{},
+		Renamed: []Change{},
+	}
+
+	oldByCode := make(map[string]Gitmoji, len(oldList))
+
+	for _, g := range oldList {
+		oldByCode[g.Code] = g
+	}
+
+	newCodes := make(map[string]bool, len(newList))
+	var unmatched []Gitmoji
+
+	for _, g := range newList {
+		newCodes[g.Code] = true
+		old, ok := oldByCode[g.Code]
+
+		if !ok {
+			unmatched = append(unmatched, g)
+		} else if old.Description != g.Description {
+			diff.Changed = append(diff.Changed, Change{Old: old, New: g})
+		}
+	}
+
+	// Old gitmoji whose code is gone may have been renamed
+	byEmoji := make(map[string]Gitmoji)
+	byName := make(map[string]Gitmoji)
+
+	for _, g := range oldList {
+		if !newCodes[g.Code] {
+			byEmoji[NormalizeEmoji(g.Emoji)] = g
+			byName[g.Name] = g
+		}
+	}
+
+	renamed := make(map[string]bool)
+
+	for _, g := range unmatched {
+		old, ok := byEmoji[NormalizeEmoji(g.Emoji)]
+
+		if !ok || g.Emoji == "" {
+			old, ok = byName[g.Name]
+			ok = ok && g.Name != ""
+		}
+
+		if ok && !renamed[old.Code] {
+			renamed[old.Code] = true
+			diff.Renamed = append(diff.Renamed, Change{Old: old, New: g})
+		} else {
+			diff.Added = append(diff.Added, g)
+		}
+	}
+
+	for _, g := range oldList {
+		if !newCodes[g.Code] && !renamed[g.Code] {
+			diff.Removed = append(diff.Removed, g)
+		}
+	}
+
+	return diff
+}
+
+// NormalizeEmoji removes the variation selector that is sometimes added to, or
+// dropped from, an emoji, e.g. between versions of the list, so that emoji can
+// be compared.
+func NormalizeEmoji(emoji string) string {
+	return strings.ReplaceAll(emoji, "\ufe0f", "")
+}
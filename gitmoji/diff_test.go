@@ -0,0 +1,40 @@
+package gitmoji
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	assert := assert.New(t)
+
+	art := Gitmoji{Emoji: "🎨", Code: ":art:", Description: "Improving structure / format of the code.", Name: "art"}
+	newArt := Gitmoji{Emoji: "🎨", Code: ":art:", Description: "Improve structure / format of the code.", Name: "art"}
+	pencil := Gitmoji{Emoji: "📝", Code: ":pencil:", Description: "Writing docs.", Name: "pencil"}
+	memo := Gitmoji{Emoji: "📝", Code: ":memo:", Description: "Add or update documentation.", Name: "memo"}
+	ambulance := Gitmoji{Emoji: "🚑", Code: ":ambulance:", Description: "Critical hotfix.", Name: "ambulance"}
+	newAmbulance := Gitmoji{Emoji: "🚑️", Code: ":ambulance:", Description: "Critical hotfix.", Name: "ambulance"}
+	beers := Gitmoji{Emoji: "🍻", Code: ":beers:", Description: "Writing code drunkenly.", Name: "beers"}
+	thread := Gitmoji{Emoji: "🧵", Code: ":thread:", Description: "Add or update code related to multithreading or concurrency.", Name: "thread"}
+
+	diff := Compare(
+		[]Gitmoji{art, pencil, ambulance, beers},
+		[]Gitmoji{newArt, memo, newAmbulance, thread},
+	)
+
+	assert.Equal([]Gitmoji{thread}, diff.Added)
+	assert.Equal([]Gitmoji{beers}, diff.Removed)
+	assert.Equal([]Change{{Old: art, New: newArt}}, diff.Changed)
+	assert.Equal([]Change{{Old: pencil, New: memo}}, diff.Renamed)
+	assert.False(diff.IsEmpty())
+}
+
+func TestCompareSameList(t *testing.T) {
+	list := []Gitmoji{
+		{Emoji: "🎨", Code: ":art:", Description: "Improve structure / format of the code.", Name: "art"},
+	}
+
+	assert.True(t, Compare(list, list).IsEmpty())
+	assert.True(t, Compare(nil, nil).IsEmpty())
+}
@@ -0,0 +1,121 @@
+package gitmoji
+
+// builtinKeywords are synonyms for the gitmoji in the upstream list, so that
+// searching for what a change is about finds the right gitmoji even when the
+// words don't appear in its code, name or description.
+var builtinKeywords = map[string][]string{
+	":art:":                       {"format", "style", "structure", "lint", "prettier", "cleanup"},
+	":zap:":                       {"performance", "perf", "speed", "fast", "optimize"},
+	":fire:":                      {"remove", "delete", "prune", "drop"},
+	":bug:":                       {"bug", "fix", "defect", "issue", "error"},
+	":ambulance:":                 {"hotfix", "urgent", "critical", "emergency", "fix"},
+	":sparkles:":                  {"feature", "feat", "new", "add"},
+	":memo:":                      {"docs", "doc", "documentation", "readme", "changelog"},
+	":rocket:":                    {"deploy", "release", "ship"},
+	":lipstick:":                  {"ui", "css", "style", "design", "cosmetic"},
+	":tada:":                      {"init", "initial", "begin", "start", "first"},
+	":white_check_mark:":          {"test", "tests", "testing", "spec", "unit"},
+	":lock:":                      {"security", "privacy", "vulnerability", "cve"},
+	":closed_lock_with_key:":      {"secrets", "credentials", "keys", "password", "vault"},
+	":bookmark:":                  {"release", "version", "tag", "bump"},
+	":rotating_light:":            {"lint", "linter", "warnings", "compiler"},
+	":construction:":              {"wip", "progress", "draft"},
+	":green_heart:":               {"ci", "build", "pipeline", "fix"},
+	":arrow_down:":                {"deps", "dependencies", "downgrade"},
+	":arrow_up:":                  {"deps", "dependencies", "upgrade", "bump", "update"},
+	":pushpin:":                   {"deps", "dependencies", "pin", "lock"},
+	":construction_worker:":       {"ci", "build", "pipeline", "workflow", "actions"},
+	":chart_with_upwards_trend:":  {"analytics", "tracking", "metrics", "telemetry"},
+	":recycle:":                   {"refactor", "refactoring", "cleanup", "restructure"},
+	":heavy_plus_sign:":           {"deps", "dependency", "add"},
+	":heavy_minus_sign:":          {"deps", "dependency", "remove"},
+	":wrench:":                    {"config", "configuration", "settings"},
+	":hammer:":                    {"scripts", "tooling", "makefile", "build"},
+	":globe_with_meridians:":      {"i18n", "l10n", "translation", "locale", "language"},
+	":pencil2:":                   {"typo", "typos", "spelling"},
+	":poop:":                      {"hack", "debt", "todo"},
+	":rewind:":                    {"revert", "undo", "rollback"},
+	":twisted_rightwards_arrows:": {"merge", "branch"},
+	":package:":                   {"compiled", "package", "bundle", "artifact"},
+	":alien:":                     {"api", "external", "upstream"},
+	":truck:":                     {"move", "rename", "relocate"},
+	":page_facing_up:":            {"license", "copyright"},
+	":boom:":                      {"breaking", "major", "incompatible"},
+	":bento:":                     {"assets", "images", "icons", "fonts"},
+	":wheelchair:":                {"accessibility", "a11y", "aria"},
+	":bulb:":                      {"comments", "comment", "docs"},
+	":speech_balloon:":            {"text", "strings", "copy", "literals", "messages"},
+	":card_file_box:":             {"database", "db", "sql", "migration", "schema"},
+	":loud_sound:":                {"logs", "logging", "log"},
+	":mute:":                      {"logs", "logging", "log", "silence"},
+	":busts_in_silhouette:":       {"contributors", "authors", "people"},
+	":children_crossing:":         {"ux", "usability", "experience"},
+	":building_construction:":     {"architecture", "design", "structure"},
+	":iphone:":                    {"responsive", "mobile"},
+	":clown_face:":                {"mock", "mocks", "stub", "fake"},
+	":see_no_evil:":               {"gitignore", "ignore"},
+	":camera_flash:":              {"snapshot", "snapshots"},
+	":alembic:":                   {"experiment", "spike", "prototype"},
+	":mag:":                       {"seo", "search"},
+	":label:":                     {"types", "typing", "typescript"},
+	":seedling:":                  {"seed", "seeds", "fixtures"},
+	":triangular_flag_on_post:":   {"flags", "feature-flag", "toggle"},
+	":goal_net:":                  {"errors", "exception", "catch", "handling"},
+	":dizzy:":                     {"animation", "transition"},
+	":wastebasket:":               {"deprecate", "deprecation", "obsolete"},
+	":passport_control:":          {"auth", "authorization", "permissions", "roles", "acl"},
+	":adhesive_bandage:":          {"fix", "minor", "patch", "bugfix"},
+	":monocle_face:":              {"data", "exploration", "inspect"},
+	":coffin:":                    {"dead", "unused", "remove"},
+	":test_tube:":                 {"test", "failing", "red", "flaky"},
+	":necktie:":                   {"business", "logic", "domain"},
+	":stethoscope:":               {"health", "healthcheck", "probe"},
+	":bricks:":                    {"infra", "infrastructure", "terraform"},
+	":technologist:":              {"dx", "developer", "tooling"},
+	":money_with_wings:":          {"sponsor", "money", "billing", "payments"},
+	":thread:":                    {"concurrency", "threads", "async", "parallel", "goroutine"},
+	":safety_vest:":               {"validation", "validate", "sanitize"},
+	":airplane:":                  {"offline", "cache"},
+}
+
+// addKeywords returns the gitmoji list with the built-in keywords for each
+// gitmoji added to any keywords it already has.
+func addKeywords(list []Gitmoji) []Gitmoji {
+	result := make([]Gitmoji, len(list))
+
+	for i, g := range list {
+		if keywords, ok := builtinKeywords[g.Code]; ok {
+			g.Keywords = appendMissing(g.Keywords, keywords)
+		}
+
+		result[i] = g
+	}
+
+	return result
+}
+
+// appendMissing appends the values that are not already in the slice.
+func appendMissing(slice []string, values []string) []string {
+	if len(values) == 0 {
+		return slice
+	}
+
+	result := append([]string{}, slice...)
+
+	for _, v := range values {
+		found := false
+
+		for _, s := range result {
+			if s == v {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
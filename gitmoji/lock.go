@@ -0,0 +1,66 @@
+package gitmoji
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LockFileSuffix is appended to the name of the cache file to give the name
+// of the lock file that guards updates to the cache.
+const LockFileSuffix string = ".lock"
+
+// lockTimeout is how long to wait for another process to release the lock.
+var lockTimeout = 30 * time.Second
+
+const lockRetryInterval = 100 * time.Millisecond
+
+// lock takes an advisory lock on the given file by locking a lock file next to
+// it, waiting for other processes to release the lock if necessary. It returns
+// a function that releases the lock.
+//
+// The lock is held by the operating system, so it is released when the process
+// exits, however it exits. The lock file is left in place, because removing it
+// would let a process waiting on the removed file take the lock at the same
+// time as one that created it anew.
+func lock(file string) (func(), error) {
+	lockFile := file + LockFileSuffix
+	err := os.MkdirAll(filepath.Dir(lockFile), 0755)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to create gitmoji cache directory: %v", err)
+	}
+
+	// #nosec G304
+	f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_RDWR, 0600)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to create lock file %s: %v", lockFile, err)
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		locked, err := tryLock(f)
+
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("unable to lock %s: %v", lockFile, err)
+		}
+
+		if locked {
+			return func() {
+				unlockFile(f)
+				f.Close()
+			}, nil
+		}
+
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("timed out waiting for another gitmoji to release the lock on %s", lockFile)
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
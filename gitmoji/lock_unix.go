@@ -0,0 +1,25 @@
+//go:build !windows
+
+package gitmoji
+
+import (
+	"os"
+	"syscall"
+)
+
+// tryLock takes an exclusive lock on the file without waiting, and reports
+// whether it was taken.
+func tryLock(f *os.File) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// unlockFile releases the lock taken by tryLock.
+func unlockFile(f *os.File) {
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,129 @@
+package gitmoji
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Scores given by Score, from the best kind of match to the worst.
+const (
+	scoreExact       = 100
+	scorePrefix      = 80
+	scoreSubstring   = 60
+	scoreWord        = 50
+	scoreDescription = 30
+	scoreFuzzy       = 10
+)
+
+// Score returns how well a gitmoji matches a search query, or zero if it does
+// not match. Matches against the gitmoji's code, name and keywords score
+// highest, then matches of whole words of the description, then matches
+// anywhere in the description; a query whose letters merely appear in order
+// in the code, name or a keyword scores lowest.
+func Score(g Gitmoji, query string) int {
+	q := normalize(query)
+
+	if q == "" {
+		return scoreFuzzy
+	}
+
+	terms := append([]string{normalize(g.Code), normalize(g.Name)}, g.Keywords...)
+	best := 0
+
+	for _, term := range terms {
+		term = normalize(term)
+
+		switch {
+		case term == "":
+			continue
+		case term == q:
+			return scoreExact
+		case strings.HasPrefix(term, q):
+			best = max(best, scorePrefix)
+		case strings.Contains(term, q):
+			best = max(best, scoreSubstring)
+		case isSubsequence(q, term):
+			best = max(best, scoreFuzzy)
+		}
+	}
+
+	if best >= scoreWord {
+		return best
+	}
+
+	for _, word := range strings.FieldsFunc(strings.ToLower(g.Description), isNotLetterOrDigit) {
+		if strings.HasPrefix(word, q) {
+			return scoreWord
+		}
+	}
+
+	if strings.Contains(normalize(g.Name+g.Code+g.Description), q) {
+		return scoreDescription
+	}
+
+	return best
+}
+
+// Search returns the gitmoji that match the query, best match first. Gitmoji
+// that match equally well stay in the order they have in the list.
+func Search(list []Gitmoji, query string) []Gitmoji {
+	type scored struct {
+		gitmoji Gitmoji
+		score   int
+	}
+
+	var matches []scored
+
+	for _, g := range list {
+		if score := Score(g, query); score > 0 {
+			matches = append(matches, scored{g, score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	result := make([]Gitmoji, len(matches))
+
+	for i, m := range matches {
+		result[i] = m.gitmoji
+	}
+
+	return result
+}
+
+// normalize lower-cases a search term and removes the spaces and punctuation
+// that vary between codes, names and what users type, e.g. ":white_check_mark:"
+// and "white-check-mark" both become "whitecheckmark".
+func normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isNotLetterOrDigit(r) {
+			return -1
+		}
+
+		return unicode.ToLower(r)
+	}, s)
+}
+
+func isNotLetterOrDigit(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// isSubsequence reports whether the letters of q appear in s in order.
+func isSubsequence(q string, s string) bool {
+	rest := []rune(q)
+
+	for _, r := range s {
+		if len(rest) == 0 {
+			break
+		}
+
+		if r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+
+	return len(rest) == 0
+}
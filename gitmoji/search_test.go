@@ -0,0 +1,61 @@
+package gitmoji
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func searchCodes(list []Gitmoji, query string) []string {
+	var codes []string
+
+	for _, g := range Search(list, query) {
+		codes = append(codes, g.Code)
+	}
+
+	return codes
+}
+
+func TestSearch(t *testing.T) {
+	assert := assert.New(t)
+
+	list, err := validateGitmoji(builtinGitmoji)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list = addKeywords(list)
+
+	// The exact match comes first, even though it's not first in the list
+	assert.Equal(":bug:", searchCodes(list, "bug")[0])
+	assert.Equal(":white_check_mark:", searchCodes(list, "test")[0])
+
+	// Keywords find gitmoji that don't mention the query
+	deps := searchCodes(list, "deps")
+	assert.Subset(deps[:5], []string{":arrow_down:", ":arrow_up:", ":pushpin:", ":heavy_plus_sign:", ":heavy_minus_sign:"})
+
+	// Codes can be typed with or without punctuation
+	assert.Equal(":white_check_mark:", searchCodes(list, ":white_check_mark:")[0])
+	assert.Equal(":white_check_mark:", searchCodes(list, "white check")[0])
+
+	// Fuzzy matches are found, but last
+	assert.Contains(searchCodes(list, "wcm"), ":white_check_mark:")
+
+	assert.Empty(searchCodes(list, "xyzzy"))
+}
+
+func TestScore(t *testing.T) {
+	assert := assert.New(t)
+
+	bug := Gitmoji{Emoji: "🐛", Code: ":bug:", Description: "Fix a bug.", Name: "bug", Keywords: []string{"defect"}}
+
+	assert.Equal(scoreExact, Score(bug, "bug"))
+	assert.Equal(scoreExact, Score(bug, "Defect"))
+	assert.Equal(scorePrefix, Score(bug, "def"))
+	assert.Equal(scoreSubstring, Score(bug, "fect"))
+	assert.Equal(scoreWord, Score(bug, "fix"))
+	assert.Equal(scoreDescription, Score(bug, "ix a"))
+	assert.Equal(scoreFuzzy, Score(bug, "dft"))
+	assert.Equal(0, Score(bug, "feature"))
+}
@@ -0,0 +1,257 @@
+// Package history records which gitmoji the user chooses, so that the ones
+// they use most frequently and most recently can be offered first.
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/jamesdobson/gogitmoji/git"
+	"github.com/jamesdobson/gogitmoji/gitmoji"
+	"github.com/jamesdobson/gogitmoji/xdg"
+)
+
+// FileName is the name of the history file in gogitmoji's state directory.
+const FileName = "history.json"
+
+// PerRepoSetting is the setting that, when true, keeps a separate history for
+// each repository rather than one for all of them.
+const PerRepoSetting = "history.per-repo"
+
+// Use records how often and when a gitmoji was last chosen.
+type Use struct {
+	Code  string    `json:"code"`
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+}
+
+// History is the record of the gitmoji chosen in one scope: either all
+// repositories, or a single repository.
+type History struct {
+	file  string
+	scope string
+	uses  []Use
+}
+
+// historyFile is the content of the history file. Scopes are keyed by the
+// path of the repository's working tree, or by the empty string for the
+// history shared by all repositories.
+type historyFile struct {
+	Scopes map[string][]Use `json:"scopes"`
+}
+
+// DefaultFile returns the path of the user's history file.
+func DefaultFile() (string, error) {
+	dir, err := xdg.StateDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, FileName), nil
+}
+
+// New opens the user's history. If the history.per-repo setting is true and
+// gogitmoji is run in a git repository, the repository's own history is
+// opened.
+func New() (*History, error) {
+	file, err := DefaultFile()
+
+	if err != nil {
+		return nil, err
+	}
+
+	scope := ""
+
+	if viper.GetBool(PerRepoSetting) {
+		if topLevel, err := git.TopLevel(); err == nil {
+			scope = topLevel
+		}
+	}
+
+	return Open(file, scope)
+}
+
+// Open reads the history of the given scope from the history file. A missing
+// file is an empty history.
+func Open(file string, scope string) (*History, error) {
+	contents, err := readFile(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &History{
+		file:  file,
+		scope: scope,
+		uses:  contents.Scopes[scope],
+	}, nil
+}
+
+// Uses returns the gitmoji in the history, most frecent first.
+func (h *History) Uses(now time.Time) []Use {
+	uses := append([]Use{}, h.uses...)
+
+	sort.SliceStable(uses, func(i, j int) bool {
+		return score(uses[i], now) > score(uses[j], now)
+	})
+
+	return uses
+}
+
+// Sort sorts the list of gitmoji so that the most frecent come first; that is,
+// the ones that have been chosen most often, giving more weight to recent
+// choices. Gitmoji that have never been chosen keep their order at the end.
+func (h *History) Sort(list []gitmoji.Gitmoji, now time.Time) []gitmoji.Gitmoji {
+	scores := make(map[string]float64, len(h.uses))
+
+	for _, use := range h.uses {
+		scores[use.Code] = score(use, now)
+	}
+
+	sorted := append([]gitmoji.Gitmoji{}, list...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return scores[sorted[i].Code] > scores[sorted[j].Code]
+	})
+
+	return sorted
+}
+
+// Record records that the gitmoji with the given code was chosen, and saves
+// the history.
+func (h *History) Record(code string, now time.Time) error {
+	found := false
+
+	for i := range h.uses {
+		if h.uses[i].Code == code {
+			h.uses[i].Count++
+			h.uses[i].Last = now
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		h.uses = append(h.uses, Use{Code: code, Count: 1, Last: now})
+	}
+
+	return h.save()
+}
+
+// Reset clears the history and saves it.
+func (h *History) Reset() error {
+	h.uses = nil
+
+	return h.save()
+}
+
+// save writes the history to the history file, keeping the other scopes'
+// histories. The file is read again first, since another run of gogitmoji may
+// have changed it since it was opened.
+func (h *History) save() error {
+	contents, err := readFile(h.file)
+
+	if err != nil {
+		return err
+	}
+
+	if len(h.uses) == 0 {
+		delete(contents.Scopes, h.scope)
+	} else {
+		contents.Scopes[h.scope] = h.uses
+	}
+
+	data, err := json.MarshalIndent(contents, "", "  ")
+
+	if err != nil {
+		return fmt.Errorf("unable to encode history: %v", err)
+	}
+
+	return writeFile(h.file, data)
+}
+
+// score weighs the number of times a gitmoji was chosen by how recently it was
+// last chosen.
+func score(use Use, now time.Time) float64 {
+	age := now.Sub(use.Last)
+	weight := 0.1
+
+	switch {
+	case age < 4*24*time.Hour:
+		weight = 1
+	case age < 14*24*time.Hour:
+		weight = 0.7
+	case age < 31*24*time.Hour:
+		weight = 0.5
+	case age < 90*24*time.Hour:
+		weight = 0.3
+	}
+
+	return float64(use.Count) * weight
+}
+
+func readFile(file string) (historyFile, error) {
+	contents := historyFile{}
+	data, err := os.ReadFile(file)
+
+	if err != nil && !os.IsNotExist(err) {
+		return contents, fmt.Errorf("unable to read history: %v", err)
+	}
+
+	if err == nil {
+		err = json.Unmarshal(data, &contents)
+
+		if err != nil {
+			return contents, fmt.Errorf("unable to read history; perhaps %s is corrupted? %v", file, err)
+		}
+	}
+
+	if contents.Scopes == nil {
+		contents.Scopes = map[string][]Use{}
+	}
+
+	return contents, nil
+}
+
+// writeFile replaces the history file by renaming a temporary file over it,
+// so that it is never left partially written.
+func writeFile(file string, data []byte) error {
+	dir := filepath.Dir(file)
+	err := os.MkdirAll(dir, 0755)
+
+	if err != nil {
+		return fmt.Errorf("unable to create history directory: %v", err)
+	}
+
+	f, err := os.CreateTemp(dir, filepath.Base(file)+".*.tmp")
+
+	if err != nil {
+		return fmt.Errorf("unable to write history: %v", err)
+	}
+
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(data)
+	closeErr := f.Close()
+
+	if err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), file)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to write history: %v", err)
+	}
+
+	return nil
+}
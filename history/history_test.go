@@ -0,0 +1,101 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jamesdobson/gogitmoji/gitmoji"
+)
+
+var testList = []gitmoji.Gitmoji{
+	{Emoji: "🎨", Code: ":art:", Description: "Improve structure / format of the code."},
+	{Emoji: "🐛", Code: ":bug:", Description: "Fix a bug."},
+	{Emoji: "✨", Code: ":sparkles:", Description: "Introduce new features."},
+	{Emoji: "📝", Code: ":memo:", Description: "Add or update documentation."},
+}
+
+func codes(list []gitmoji.Gitmoji) []string {
+	result := make([]string, len(list))
+
+	for i, g := range list {
+		result[i] = g.Code
+	}
+
+	return result
+}
+
+func TestSortByFrecency(t *testing.T) {
+	assert := assert.New(t)
+	file := filepath.Join(t.TempDir(), "state", FileName)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	h, err := Open(file, "")
+	assert.NoError(err)
+	assert.Equal(codes(testList), codes(h.Sort(testList, now)))
+
+	// Chosen often, but long ago
+	for i := 0; i < 5; i++ {
+		assert.NoError(h.Record(":memo:", now.AddDate(-1, 0, 0)))
+	}
+
+	// Chosen less often, but recently
+	assert.NoError(h.Record(":bug:", now.Add(-time.Hour)))
+	assert.NoError(h.Record(":bug:", now))
+	assert.NoError(h.Record(":sparkles:", now))
+
+	assert.Equal([]string{":bug:", ":sparkles:", ":memo:", ":art:"}, codes(h.Sort(testList, now)))
+
+	uses := h.Uses(now)
+	assert.Equal(":bug:", uses[0].Code)
+	assert.Equal(2, uses[0].Count)
+	assert.Equal(now, uses[0].Last)
+
+	// The history was saved
+	h, err = Open(file, "")
+	assert.NoError(err)
+	assert.Equal([]string{":bug:", ":sparkles:", ":memo:", ":art:"}, codes(h.Sort(testList, now)))
+}
+
+func TestScopes(t *testing.T) {
+	assert := assert.New(t)
+	file := filepath.Join(t.TempDir(), FileName)
+	now := time.Now()
+
+	global, err := Open(file, "")
+	assert.NoError(err)
+	assert.NoError(global.Record(":memo:", now))
+
+	repo, err := Open(file, "/src/repo")
+	assert.NoError(err)
+	assert.Empty(repo.Uses(now))
+	assert.NoError(repo.Record(":bug:", now))
+
+	// Resetting one scope keeps the others
+	assert.NoError(repo.Reset())
+
+	global, err = Open(file, "")
+	assert.NoError(err)
+	assert.Len(global.Uses(now), 1)
+
+	repo, err = Open(file, "/src/repo")
+	assert.NoError(err)
+	assert.Empty(repo.Uses(now))
+}
+
+func TestCorruptedHistory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), FileName)
+
+	if err := os.WriteFile(file, []byte("{\"scopes\": "), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Open(file, "")
+
+	if err == nil {
+		t.Fatal("Expected error reading corrupted history.")
+	}
+}
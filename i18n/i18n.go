@@ -0,0 +1,187 @@
+// Package i18n translates gogitmoji's messages and the descriptions of
+// gitmoji into the user's language.
+//
+// Translations come from catalogs: YAML files named after a locale, such as
+// "pt.yaml" or "pt_BR.yaml". Catalogs for a few languages are built in, and
+// users can add their own, or override the built-in ones, in the locales
+// directory under gogitmoji's config directory. Anything without a
+// translation is left in English.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+
+	"github.com/jamesdobson/gogitmoji/xdg"
+)
+
+// LocaleSetting is the name of the setting giving the locale to translate
+// into, e.g. "pt_BR". When unset, the locale is taken from the LC_ALL,
+// LC_MESSAGES or LANG environment variables.
+const LocaleSetting = "locale"
+
+// LocalesDirName is the name of the directory, under gogitmoji's config
+// directory, that holds the user's catalogs.
+const LocalesDirName = "locales"
+
+// Catalog holds translations for one locale. Messages are keyed by their
+// English text; gitmoji descriptions are keyed by the gitmoji code.
+type Catalog struct {
+	Messages map[string]string `yaml:"messages"`
+	Gitmojis map[string]string `yaml:"gitmojis"`
+}
+
+//go:embed locales/*.yaml
+var bundled embed.FS
+
+var current = newCatalog()
+
+// Load loads the catalogs for the configured locale. Catalogs for the
+// language as a whole are loaded before those for the language's regional
+// variant, and the user's catalogs are loaded after the built-in ones, with
+// later catalogs overriding earlier ones.
+func Load() error {
+	catalog := newCatalog()
+	localesDir, err := LocalesDir()
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range catalogNames(Locale()) {
+		if content, err := bundled.ReadFile("locales/" + name + ".yaml"); err == nil {
+			if err := catalog.merge(content); err != nil {
+				return fmt.Errorf("invalid built-in translations for %s: %v", name, err)
+			}
+		}
+
+		userFile := filepath.Join(localesDir, name+".yaml")
+		// #nosec G304
+		content, err := os.ReadFile(userFile)
+
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return fmt.Errorf("unable to read translations: %v", err)
+		}
+
+		err = catalog.merge(content)
+
+		if err != nil {
+			return fmt.Errorf("invalid translations in %s: %v", userFile, err)
+		}
+	}
+
+	current = catalog
+
+	return nil
+}
+
+// LocalesDir returns the directory that holds the user's catalogs.
+func LocalesDir() (string, error) {
+	configDir, err := xdg.ConfigDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, LocalesDirName), nil
+}
+
+// Locale returns the locale to translate into, as configured.
+func Locale() string {
+	if locale := viper.GetString(LocaleSetting); locale != "" {
+		return locale
+	}
+
+	for _, variable := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(variable); locale != "" {
+			return locale
+		}
+	}
+
+	return ""
+}
+
+// T returns the translation of the given English message, or the message
+// itself if it has no translation.
+func T(message string) string {
+	if translated, ok := current.Messages[message]; ok && translated != "" {
+		return translated
+	}
+
+	return message
+}
+
+// Descriptions returns the translated descriptions of gitmoji, keyed by the
+// gitmoji code.
+func Descriptions() map[string]string {
+	descriptions := make(map[string]string, len(current.Gitmojis))
+
+	for code, description := range current.Gitmojis {
+		if description != "" {
+			descriptions[code] = description
+		}
+	}
+
+	return descriptions
+}
+
+// catalogNames returns the names of the catalogs for a locale, from the least
+// to the most specific. For example, "pt_BR.UTF-8" gives "pt" and "pt_BR".
+// The "C" and "POSIX" locales have no catalogs.
+func catalogNames(locale string) []string {
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+
+	language, region, _ := strings.Cut(strings.ReplaceAll(locale, "-", "_"), "_")
+	language = strings.ToLower(language)
+
+	if language == "" || language == "c" || language == "posix" {
+		return nil
+	}
+
+	names := []string{language}
+
+	if region != "" {
+		names = append(names, language+"_"+strings.ToUpper(region))
+	}
+
+	return names
+}
+
+func newCatalog() Catalog {
+	return Catalog{
+		Messages: map[string]string{},
+		Gitmojis: map[string]string{},
+	}
+}
+
+func (catalog *Catalog) merge(content []byte) error {
+	var c Catalog
+
+	err := yaml.Unmarshal(content, &c)
+
+	if err != nil {
+		return err
+	}
+
+	for k, v := range c.Messages {
+		catalog.Messages[k] = v
+	}
+
+	for k, v := range c.Gitmojis {
+		catalog.Gitmojis[k] = v
+	}
+
+	return nil
+}
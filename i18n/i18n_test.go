@@ -0,0 +1,161 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogNames(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"pt", "pt_BR"}, catalogNames("pt_BR.UTF-8"))
+	assert.Equal([]string{"pt", "pt_BR"}, catalogNames("pt-br"))
+	assert.Equal([]string{"ja"}, catalogNames("ja"))
+	assert.Equal([]string{"de", "de_DE"}, catalogNames("de_DE@euro"))
+	assert.Equal([]string{"en", "en_US"}, catalogNames("en_US.UTF-8"))
+	assert.Nil(catalogNames("C"))
+	assert.Nil(catalogNames("POSIX"))
+	assert.Nil(catalogNames(""))
+}
+
+func TestLoad(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { current = newCatalog() }()
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "pt_BR.UTF-8")
+
+	// The user's catalog for the regional variant overrides the built-in one
+	localesDir := filepath.Join(configHome, "gitmoji", LocalesDirName)
+	assert.NoError(os.MkdirAll(localesDir, 0755))
+	assert.NoError(os.WriteFile(filepath.Join(localesDir, "pt_BR.yaml"), []byte(`
+messages:
+  "Choose a gitmoji": "Escolha um gitmoji, por favor"
+gitmojis:
+  ":fire_extinguisher:": "Colocar um teste instável em quarentena."
+`), 0600))
+
+	assert.NoError(Load())
+	assert.Equal("Escolha um gitmoji, por favor", T("Choose a gitmoji"))
+	assert.Equal("Cancelado.", T("Canceled."))
+	assert.Equal("No translation", T("No translation"))
+
+	descriptions := Descriptions()
+	assert.Equal("Corrigir um bug.", descriptions[":bug:"])
+	assert.Equal("Colocar um teste instável em quarentena.", descriptions[":fire_extinguisher:"])
+	assert.NotContains(descriptions, ":unknown:")
+
+	// The locale setting wins over the environment
+	viper.Set(LocaleSetting, "ja")
+	defer viper.Set(LocaleSetting, "")
+
+	assert.NoError(Load())
+	assert.Equal("gitmojiを選んでください", T("Choose a gitmoji"))
+}
+
+func TestBundledCatalogs(t *testing.T) {
+	messages := translatedMessages(t)
+
+	if len(messages) == 0 {
+		t.Fatal("Expected to find messages to translate.")
+	}
+
+	entries, err := bundled.ReadDir("locales")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		content, err := bundled.ReadFile("locales/" + entry.Name())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		catalog := newCatalog()
+
+		if err := catalog.merge(content); err != nil {
+			t.Errorf("Invalid catalog %s: %v", entry.Name(), err)
+		}
+
+		if len(catalog.Messages) == 0 || len(catalog.Gitmojis) == 0 {
+			t.Errorf("Catalog %s is missing translations.", entry.Name())
+		}
+
+		for _, message := range messages {
+			if _, ok := catalog.Messages[message]; !ok {
+				t.Errorf("Catalog %s has no translation of %q.", entry.Name(), message)
+			}
+		}
+	}
+}
+
+// translatedMessages returns the string literals passed to i18n.T in the
+// source files of the module, other than tests.
+func translatedMessages(t *testing.T) []string {
+	var messages []string
+
+	err := filepath.WalkDir("..", func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+			return err
+		}
+
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+
+		if err != nil {
+			return err
+		}
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+
+			if !ok || len(call.Args) != 1 {
+				return true
+			}
+
+			fun, ok := call.Fun.(*ast.SelectorExpr)
+
+			if !ok || fun.Sel.Name != "T" {
+				return true
+			}
+
+			if pkg, ok := fun.X.(*ast.Ident); !ok || pkg.Name != "i18n" {
+				return true
+			}
+
+			if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				message, err := strconv.Unquote(lit.Value)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				messages = append(messages, message)
+			}
+
+			return true
+		})
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return messages
+}
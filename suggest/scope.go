@@ -0,0 +1,88 @@
+package suggest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/jamesdobson/gogitmoji/git"
+)
+
+// ScopesSetting is the setting that maps paths in the repository to the scopes
+// they belong to.
+const ScopesSetting = "suggest.scopes"
+
+// ScopeRule gives the scope of the files that match any of its glob patterns,
+// which are matched in the same way as a Rule's.
+type ScopeRule struct {
+	Files []string `mapstructure:"files"`
+	Scope string   `mapstructure:"scope"`
+}
+
+// StagedScopes returns the scopes suggested for the changes staged in the
+// current repository, the one with the most changed files first. Nothing is
+// suggested when nothing is staged, or when not in a git repository.
+func StagedScopes() ([]string, error) {
+	var rules []ScopeRule
+
+	err := viper.UnmarshalKey(ScopesSetting, &rules)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", ScopesSetting, err)
+	}
+
+	changes, err := git.StagedChanges()
+
+	if err != nil {
+		return nil, nil
+	}
+
+	return Scopes(rules, changes), nil
+}
+
+// Scopes returns the scopes of the changed files, the one with the most files
+// first. The scope of a file is given by the first rule that matches it or,
+// if none do, is the top-level directory that the file is in. Files at the
+// root of the repository have no scope.
+func Scopes(rules []ScopeRule, changes []git.Change) []string {
+	var scopes []string
+	counts := map[string]int{}
+
+	for _, change := range changes {
+		scope := scopeOf(rules, change.Path)
+
+		if scope == "" {
+			continue
+		}
+
+		if counts[scope] == 0 {
+			scopes = append(scopes, scope)
+		}
+
+		counts[scope]++
+	}
+
+	sort.SliceStable(scopes, func(i, j int) bool {
+		return counts[scopes[i]] > counts[scopes[j]]
+	})
+
+	return scopes
+}
+
+func scopeOf(rules []ScopeRule, file string) string {
+	for _, rule := range rules {
+		for _, pattern := range rule.Files {
+			if matchFile(pattern, file) {
+				return rule.Scope
+			}
+		}
+	}
+
+	if i := strings.Index(file, "/"); i > 0 {
+		return file[:i]
+	}
+
+	return ""
+}
@@ -0,0 +1,43 @@
+package suggest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jamesdobson/gogitmoji/git"
+)
+
+func TestScopes(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Empty(Scopes(nil, nil))
+	assert.Empty(Scopes(nil, []git.Change{{Path: "README.md"}}))
+	assert.Equal([]string{"gitmoji"}, Scopes(nil, []git.Change{
+		{Path: "gitmoji/cache.go"},
+		{Path: "gitmoji/cache_test.go"},
+		{Path: "README.md"},
+	}))
+	assert.Equal([]string{"tmpl", "cmd"}, Scopes(nil, []git.Change{
+		{Path: "cmd/commit.go"},
+		{Path: "tmpl/template.go"},
+		{Path: "tmpl/template_test.go"},
+	}))
+}
+
+func TestScopeRules(t *testing.T) {
+	assert := assert.New(t)
+	rules := []ScopeRule{
+		{Files: []string{"services/billing/**", "proto/billing.proto"}, Scope: "billing"},
+		{Files: []string{"*.md"}, Scope: "docs"},
+	}
+
+	assert.Equal([]string{"billing", "docs"}, Scopes(rules, []git.Change{
+		{Path: "services/billing/invoice.go"},
+		{Path: "proto/billing.proto"},
+		{Path: "README.md"},
+	}))
+	assert.Equal([]string{"services"}, Scopes(rules, []git.Change{
+		{Path: "services/shipping/label.go"},
+	}))
+}
@@ -0,0 +1,168 @@
+// Package suggest suggests gitmoji and scopes for a commit by looking at the
+// changes that are staged for it.
+package suggest
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/jamesdobson/gogitmoji/git"
+)
+
+// RulesSetting is the setting that holds the user's and the repository's
+// suggestion rules.
+const RulesSetting = "suggest.rules"
+
+// BuiltinSetting is the setting that, when false, turns off the built-in
+// suggestion rules.
+const BuiltinSetting = "suggest.builtin"
+
+// Rule suggests a gitmoji when the staged changes match all of its
+// conditions.
+type Rule struct {
+	// Gitmoji is the code of the gitmoji to suggest, e.g. ":bug:".
+	Gitmoji string `mapstructure:"gitmoji"`
+
+	// Files are glob patterns. Every changed file must match one of them,
+	// unless Any is true, in which case at least one must. A pattern without a
+	// slash is matched against the file's name; otherwise, it is matched
+	// against its path from the root of the repository, and a pattern ending in
+	// "/**" matches everything under that directory.
+	Files []string `mapstructure:"files"`
+	Any   bool     `mapstructure:"any"`
+
+	// RemovedOnly requires that lines were removed and none were added.
+	RemovedOnly bool `mapstructure:"removed-only"`
+}
+
+// BuiltinRules are the rules that are used after the user's and the
+// repository's own rules, unless the suggest.builtin setting is false.
+var BuiltinRules = []Rule{
+	{Gitmoji: ":fire:", Files: []string{"*"}, RemovedOnly: true},
+	{Gitmoji: ":white_check_mark:", Files: []string{
+		"*_test.go", "test_*.py", "*_test.py", "*.test.js", "*.test.ts", "*.spec.js", "*.spec.ts",
+	}},
+	{Gitmoji: ":arrow_up:", Files: []string{
+		"go.mod", "go.sum", "package.json", "package-lock.json", "yarn.lock", "Cargo.toml",
+		"Cargo.lock", "requirements.txt", "Gemfile", "Gemfile.lock",
+	}},
+	{Gitmoji: ":memo:", Files: []string{"*.md", "*.rst", "*.adoc", "docs/**"}},
+	{Gitmoji: ":see_no_evil:", Files: []string{".gitignore"}},
+	{Gitmoji: ":construction_worker:", Files: []string{
+		".github/workflows/**", ".gitlab-ci.yml", ".travis.yml", "Jenkinsfile",
+	}},
+	{Gitmoji: ":page_facing_up:", Files: []string{"LICENSE", "LICENSE.*", "COPYING"}},
+}
+
+// Staged returns the codes of the gitmoji suggested for the changes staged
+// in the current repository, best first. Nothing is suggested when nothing is
+// staged, or when not in a git repository.
+func Staged() ([]string, error) {
+	var rules []Rule
+
+	err := viper.UnmarshalKey(RulesSetting, &rules)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", RulesSetting, err)
+	}
+
+	if !viper.IsSet(BuiltinSetting) || viper.GetBool(BuiltinSetting) {
+		rules = append(rules, BuiltinRules...)
+	}
+
+	changes, err := git.StagedChanges()
+
+	if err != nil {
+		return nil, nil
+	}
+
+	return Suggest(rules, changes), nil
+}
+
+// Suggest returns the codes of the gitmoji of the rules that match the
+// changes, in the order of the rules, without duplicates.
+func Suggest(rules []Rule, changes []git.Change) []string {
+	var codes []string
+
+	if len(changes) == 0 {
+		return codes
+	}
+
+	for _, rule := range rules {
+		if rule.matches(changes) && !contains(codes, rule.Gitmoji) {
+			codes = append(codes, rule.Gitmoji)
+		}
+	}
+
+	return codes
+}
+
+func (rule Rule) matches(changes []git.Change) bool {
+	if rule.RemovedOnly {
+		added, removed := 0, 0
+
+		for _, change := range changes {
+			added += change.Added
+			removed += change.Removed
+		}
+
+		if added > 0 || removed == 0 {
+			return false
+		}
+	}
+
+	if len(rule.Files) == 0 {
+		return true
+	}
+
+	for _, change := range changes {
+		matched := rule.matchesFile(change.Path)
+
+		if rule.Any && matched {
+			return true
+		}
+
+		if !rule.Any && !matched {
+			return false
+		}
+	}
+
+	return !rule.Any
+}
+
+func (rule Rule) matchesFile(file string) bool {
+	for _, pattern := range rule.Files {
+		if matchFile(pattern, file) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchFile(pattern string, file string) bool {
+	if dir := strings.TrimSuffix(pattern, "/**"); dir != pattern {
+		return strings.HasPrefix(file, dir+"/")
+	}
+
+	if !strings.Contains(pattern, "/") {
+		file = path.Base(file)
+	}
+
+	matched, err := path.Match(pattern, file)
+
+	return err == nil && matched
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
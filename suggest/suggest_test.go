@@ -0,0 +1,62 @@
+package suggest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jamesdobson/gogitmoji/git"
+)
+
+func TestBuiltinRules(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Empty(Suggest(BuiltinRules, nil))
+	assert.Equal([]string{":white_check_mark:"}, Suggest(BuiltinRules, []git.Change{
+		{Path: "gitmoji/cache_test.go", Added: 10, Removed: 2},
+		{Path: "suggest/suggest_test.go", Added: 30},
+	}))
+	assert.Equal([]string{":arrow_up:"}, Suggest(BuiltinRules, []git.Change{
+		{Path: "go.mod", Added: 1, Removed: 1},
+		{Path: "go.sum", Added: 2, Removed: 2},
+	}))
+	assert.Equal([]string{":memo:"}, Suggest(BuiltinRules, []git.Change{
+		{Path: "README.md", Added: 4},
+		{Path: "docs/guide/setup.txt", Added: 4},
+	}))
+	assert.Equal([]string{":fire:", ":memo:"}, Suggest(BuiltinRules, []git.Change{
+		{Path: "docs/old.md", Removed: 40},
+	}))
+
+	// Source and tests together: no built-in rule applies
+	assert.Empty(Suggest(BuiltinRules, []git.Change{
+		{Path: "gitmoji/cache.go", Added: 10},
+		{Path: "gitmoji/cache_test.go", Added: 10},
+	}))
+}
+
+func TestCustomRules(t *testing.T) {
+	assert := assert.New(t)
+	rules := []Rule{
+		{Gitmoji: ":card_file_box:", Files: []string{"db/migrations/**"}, Any: true},
+		{Gitmoji: ":globe_with_meridians:", Files: []string{"i18n/locales/*.yaml"}},
+		{Gitmoji: ":white_check_mark:", Files: []string{"*_test.go"}},
+	}
+	changes := []git.Change{
+		{Path: "db/migrations/0042_add_index.sql", Added: 3},
+		{Path: "db/schema.go", Added: 1, Removed: 1},
+	}
+
+	assert.Equal([]string{":card_file_box:"}, Suggest(rules, changes))
+
+	changes = []git.Change{
+		{Path: "i18n/locales/pt.yaml", Added: 3},
+		{Path: "i18n/locales/ja.yaml", Added: 3},
+	}
+
+	assert.Equal([]string{":globe_with_meridians:"}, Suggest(rules, changes))
+
+	// A rule without conditions always matches; duplicates are dropped
+	rules = append(rules, Rule{Gitmoji: ":memo:"}, rules[1])
+	assert.Equal([]string{":globe_with_meridians:", ":memo:"}, Suggest(rules, changes))
+}
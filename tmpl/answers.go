@@ -0,0 +1,163 @@
+package tmpl
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jamesdobson/gogitmoji/gitmoji"
+)
+
+// Options changes how a template is run.
+type Options struct {
+	// Answers are answers given in advance, keyed by prompt name. When there
+	// are any, only the mandatory prompts that they don't answer are asked.
+	Answers map[string]interface{}
+
+	// NoInput means that nothing is asked. It is an error if a mandatory
+	// prompt has no answer in Answers.
+	NoInput bool
+
+	// DryRun means that the command is shown, but not run.
+	DryRun bool
+
+	// Print means that, instead of running the command, the template's
+	// messages are printed on standard output, separated by blank lines.
+	Print bool
+
+	// ExtraArgs are added to the end of the command's arguments.
+	ExtraArgs []string
+}
+
+// issueAnswer is the name of the answer holding the issue key from the branch
+// name, which every template has.
+const issueAnswer = "issue"
+
+// checkAnswerNames returns an error if there is an answer for a prompt that
+// the template doesn't have.
+func checkAnswerNames(tpl CommandTemplate, answers map[string]interface{}) error {
+	for name := range answers {
+		if name == issueAnswer {
+			continue
+		}
+
+		found := false
+
+		for _, question := range tpl.Prompts {
+			if question.Name == name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("there is an answer for '%s', but the template has no prompt with that name", name)
+		}
+	}
+
+	return nil
+}
+
+// presetAnswer converts an answer given in advance to the answer that the
+// prompt would give.
+func presetAnswer(question Prompt, value interface{}) (interface{}, error) {
+	s := fmt.Sprint(value)
+
+	switch question.Type {
+	case "text", "scope", "editor":
+		if err := validateAnswer(question, s); err != nil {
+			return nil, err
+		}
+
+		return s, nil
+
+	case "choice":
+		if err := checkChoice(question, s); err != nil {
+			return nil, err
+		}
+
+		return s, nil
+
+	case "multichoice":
+		return parseMultiChoice(question, value)
+
+	case "gitmoji":
+		return findGitmoji(s)
+
+	case "confirm":
+		return parseConfirm(s)
+
+	default:
+		return nil, fmt.Errorf("unknown prompt type '%s'", question.Type)
+	}
+}
+
+// checkChoice returns an error if the value is not one of the prompt's
+// choices.
+func checkChoice(question Prompt, value string) error {
+	values := make([]string, len(question.Choices))
+
+	for i, choice := range question.Choices {
+		if choice.Value == value {
+			return nil
+		}
+
+		values[i] = choice.Value
+	}
+
+	return fmt.Errorf("'%s' is not one of: %s", value, strings.Join(values, ", "))
+}
+
+// emptyAnswer is the answer to a prompt that was not asked.
+func emptyAnswer(question Prompt) interface{} {
+	switch question.Type {
+	case "gitmoji":
+		return gitmoji.Gitmoji{}
+	case "confirm":
+		return false
+	case "multichoice":
+		return []string{}
+	default:
+		return ""
+	}
+}
+
+// findGitmoji returns the gitmoji with the given code (with or without the
+// colons), emoji or name.
+func findGitmoji(s string) (gitmoji.Gitmoji, error) {
+	cache, err := gitmoji.NewCache()
+
+	if err != nil {
+		log.Panic(err)
+	}
+
+	glist, err := cache.GetGitmoji()
+
+	if err != nil {
+		log.Fatal("Unable to get list of gitmoji: ", err)
+	}
+
+	if err := cache.WaitForRefresh(); err != nil {
+		fmt.Fprintf(console, "⚠️  %v\n", err)
+	}
+
+	for _, g := range glist {
+		if s == g.Code || ":"+s+":" == g.Code || s == g.Name || gitmoji.NormalizeEmoji(s) == gitmoji.NormalizeEmoji(g.Emoji) {
+			return g, nil
+		}
+	}
+
+	return gitmoji.Gitmoji{}, fmt.Errorf("there is no gitmoji '%s'", s)
+}
+
+// parseConfirm parses the answer to a confirm prompt.
+func parseConfirm(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "y", "yes", "true", "1":
+		return true, nil
+	case "n", "no", "false", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("'%s' is not yes or no", s)
+	}
+}
@@ -0,0 +1,99 @@
+package tmpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAnswerNames(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(checkAnswerNames(conventionalCommandTemplate, nil))
+	assert.NoError(checkAnswerNames(conventionalCommandTemplate, map[string]interface{}{
+		"type":  "fix",
+		"issue": "PROJ-1",
+	}))
+	assert.Error(checkAnswerNames(conventionalCommandTemplate, map[string]interface{}{
+		"tpye": "fix",
+	}))
+}
+
+func TestPresetAnswer(t *testing.T) {
+	assert := assert.New(t)
+	choice := conventionalCommandTemplate.Prompts[0]
+	text := Prompt{Type: "text", Name: "title", Mandatory: true}
+
+	answer, err := presetAnswer(choice, "fix")
+	assert.NoError(err)
+	assert.Equal("fix", answer)
+
+	_, err = presetAnswer(choice, "bugfix")
+	assert.Error(err)
+
+	answer, err = presetAnswer(text, 42)
+	assert.NoError(err)
+	assert.Equal("42", answer)
+
+	_, err = presetAnswer(text, "")
+	assert.Error(err)
+
+	_, err = presetAnswer(Prompt{Type: "unknown"}, "x")
+	assert.Error(err)
+}
+
+func TestConfirmAnswers(t *testing.T) {
+	assert := assert.New(t)
+	breaking := Prompt{Type: "confirm", Name: "breaking"}
+
+	answer, err := presetAnswer(breaking, "yes")
+	assert.NoError(err)
+	assert.Equal(true, answer)
+
+	answer, err = presetAnswer(breaking, false)
+	assert.NoError(err)
+	assert.Equal(false, answer)
+
+	_, err = presetAnswer(breaking, "maybe")
+	assert.Error(err)
+
+	assert.Equal(false, emptyAnswer(breaking))
+}
+
+func TestBreakingChange(t *testing.T) {
+	args := conventionalCommandTemplate.CommandArgs
+	answers := map[string]interface{}{
+		"type":        "feat",
+		"description": "drop the v1 API",
+		"breaking":    true,
+		"body":        "",
+		"footer":      "",
+		"issue":       "",
+	}
+
+	assert.Equal(t, []string{"commit", "-m", "feat!: drop the v1 API"}, generateArgs(&args, answers))
+}
+
+func TestAnswerTurnsOnCondition(t *testing.T) {
+	assert := assert.New(t)
+	tpl := CommandTemplate{
+		Prompts: []Prompt{
+			{Type: "text", Name: "title", Mandatory: true},
+			{Type: "text", Name: "scope", Condition: "gitmoji-test-scope"},
+		},
+	}
+
+	answers := getAnswers(tpl, Options{
+		Answers: map[string]interface{}{"title": "stop crashing", "scope": "api"},
+		NoInput: true,
+	})
+
+	assert.Equal("api", answers["scope"])
+
+	answers = getAnswers(tpl, Options{
+		Answers: map[string]interface{}{"title": "stop crashing"},
+		NoInput: true,
+	})
+
+	assert.NotContains(answers, "scope")
+}
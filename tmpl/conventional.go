@@ -42,10 +42,14 @@ var conventionalCommandTemplate = CommandTemplate{
 		{
 			Type:      "text",
 			Mandatory: true,
-			Prompt:    "Enter the commit description, with JIRA number at end",
+			Prompt:    "Enter the commit description",
 			Name:      "description",
 		},
-		// TODO: Ask if this is a breaking change
+		{
+			Type:   "confirm",
+			Prompt: "Is this a breaking change?",
+			Name:   "breaking",
+		},
 		{
 			Type:      "text",
 			Mandatory: false,
@@ -63,16 +67,16 @@ var conventionalCommandTemplate = CommandTemplate{
 	CommandArgs: []string{
 		"commit",
 		"-m",
-		"{{.type}}: {{.description}}",
+		"{{.type}}{{if .breaking}}!{{end}}: {{.description}}",
 		"{{with .body}}-m{{end}}",
 		"{{.body}}",
-		"{{with .footer}}-m{{end}}",
-		"{{.footer}}",
+		"{{if or .footer .issue}}-m{{end}}",
+		"{{.footer}}{{if and .footer .issue}}\n{{end}}{{with .issue}}Refs: {{.}}{{end}}",
 	},
 	Messages: []string{
-		"{{.type}}: {{.description}}",
+		"{{.type}}{{if .breaking}}!{{end}}: {{.description}}",
 		"{{.body}}",
-		"{{.footer}}",
+		"{{.footer}}{{if and .footer .issue}}\n{{end}}{{with .issue}}Refs: {{.}}{{end}}",
 	},
 }
 
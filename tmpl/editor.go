@@ -0,0 +1,218 @@
+package tmpl
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+
+	"github.com/jamesdobson/gogitmoji/i18n"
+)
+
+// editorVariables are the environment variables that name the user's editor,
+// in order of preference, as git uses them.
+var editorVariables = []string{"GIT_EDITOR", "VISUAL", "EDITOR"}
+
+// endOfText is what the user enters on a line by itself to finish entering
+// text without an editor.
+const endOfText = "."
+
+// errNoEditor is returned by editText when the editor can't be started.
+var errNoEditor = errors.New("unable to start the editor")
+
+// promptEditor asks for text that may span several lines, using the user's
+// editor if they have one and it can be started, or reading lines from the
+// terminal otherwise. If the text breaks one of the prompt's rules, it is asked
+// for again.
+func promptEditor(question Prompt, initial string) string {
+	label := i18n.T(question.Prompt)
+	editor := editorCommand()
+
+	for {
+		var text string
+		var err error
+
+		if editor != "" {
+			text, err = editText(editor, label, question.Mandatory, initial)
+
+			if err == errNoEditor {
+				fmt.Fprintf(console, "⚠️  "+i18n.T("Unable to start the editor '%s', so enter the text here.")+"\n", editor)
+				editor = ""
+			}
+		}
+
+		if editor == "" {
+			text, err = promptLines(label, initial)
+		}
+
+		if err != nil {
+			if err == promptui.ErrInterrupt {
+				fmt.Fprintln(console, i18n.T("Canceled."))
+				os.Exit(1)
+			}
+
+			log.Panic(err)
+		}
+
+		if question.Mandatory && text == "" {
+			fmt.Fprintf(console, "%s: %s\n", label, i18n.T("this is required"))
+			fmt.Fprintln(console, i18n.T("Canceled."))
+			os.Exit(1)
+		}
+
+		err = validateAnswer(question, text)
+
+		if err == nil {
+			return text
+		}
+
+		fmt.Fprintf(console, "%s %s: %v\n", promptui.IconBad, label, err)
+		initial = text
+	}
+}
+
+// editorCommand returns the user's editor command, or the empty string if
+// they haven't set one.
+func editorCommand() string {
+	for _, variable := range editorVariables {
+		if editor := os.Getenv(variable); editor != "" {
+			return editor
+		}
+	}
+
+	return ""
+}
+
+// editText opens the editor on a temporary file containing the initial text
+// and a comment explaining what to enter, and returns what the user saved,
+// less the comments. If the editor can't be started, errNoEditor is returned.
+func editText(editor string, label string, mandatory bool, initial string) (string, error) {
+	f, err := os.CreateTemp("", "gitmoji-*.txt")
+
+	if err != nil {
+		return "", fmt.Errorf("unable to create file to edit: %v", err)
+	}
+
+	defer os.Remove(f.Name())
+
+	hint := i18n.T("Lines starting with '#' will be ignored, and an empty text leaves it empty.")
+
+	if mandatory {
+		hint = i18n.T("Lines starting with '#' will be ignored, and an empty text aborts.")
+	}
+
+	_, err = fmt.Fprintf(f, "%s\n\n# %s\n# %s\n", initial, label, hint)
+	closeErr := f.Close()
+
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("unable to write file to edit: %v", err)
+	}
+
+	cmd := editorProcess(editor, f.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = console
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() != commandNotFound {
+			return "", fmt.Errorf("editor '%s' failed: %v", editor, err)
+		}
+
+		return "", errNoEditor
+	}
+
+	content, err := os.ReadFile(f.Name())
+
+	if err != nil {
+		return "", fmt.Errorf("unable to read edited file: %v", err)
+	}
+
+	return cleanupText(string(content), true), nil
+}
+
+// cleanupText cleans up text the way git cleans up a commit message: trailing
+// whitespace is removed, runs of blank lines become one, and leading and
+// trailing blank lines are removed. If stripComments is true, lines starting
+// with '#' are removed too.
+func cleanupText(text string, stripComments bool) string {
+	var lines []string
+	blank := false
+
+	for _, line := range strings.Split(text, "\n") {
+		if stripComments && strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimRight(line, " \t\r")
+
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// promptLines reads lines from the terminal until the user enters a line
+// containing only a full stop. The lines of the initial text are offered in
+// turn, to be kept or edited.
+func promptLines(label string, initial string) (string, error) {
+	fmt.Fprintf(console, "%s %s %s\n", promptui.IconInitial, label,
+		promptui.Styler(promptui.FGFaint)(fmt.Sprintf(i18n.T("(end with a line containing only %q)"), endOfText)))
+
+	templates := &promptui.PromptTemplates{
+		Prompt:  `{{ "›" | faint }} `,
+		Valid:   `{{ "›" | faint }} `,
+		Invalid: `{{ "›" | faint }} `,
+		Success: `{{ "›" | faint }} `,
+	}
+
+	var lines []string
+	var initialLines []string
+
+	if initial != "" {
+		initialLines = strings.Split(initial, "\n")
+	}
+
+	for i := 0; ; i++ {
+		prompt := promptui.Prompt{
+			Templates: templates,
+			Stdout:    console,
+		}
+
+		if i < len(initialLines) {
+			prompt.Default = initialLines[i]
+			prompt.AllowEdit = true
+		}
+
+		line, err := prompt.Run()
+
+		if err != nil {
+			return "", err
+		}
+
+		if line == endOfText {
+			break
+		}
+
+		lines = append(lines, line)
+	}
+
+	return cleanupText(strings.Join(lines, "\n"), false), nil
+}
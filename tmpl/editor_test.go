@@ -0,0 +1,58 @@
+package tmpl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditorCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Setenv("GIT_EDITOR", "")
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	assert.Equal("", editorCommand())
+
+	t.Setenv("EDITOR", "nano")
+	assert.Equal("nano", editorCommand())
+
+	t.Setenv("VISUAL", "code --wait")
+	assert.Equal("code --wait", editorCommand())
+
+	t.Setenv("GIT_EDITOR", "vim")
+	assert.Equal("vim", editorCommand())
+}
+
+func TestEditText(t *testing.T) {
+	assert := assert.New(t)
+	edited := filepath.Join(t.TempDir(), "edited.txt")
+	content := "\n\nFix the crash:\n\n\n- check for nil  \n- add a test\n# Enter the commit body\n"
+
+	if err := os.WriteFile(edited, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The "editor" replaces the file with the edited text
+	text, err := editText("cp "+edited, "Enter the commit body", false, "")
+	assert.NoError(err)
+	assert.Equal("Fix the crash:\n\n- check for nil\n- add a test", text)
+
+	_, err = editText("false", "Enter the commit body", false, "")
+	assert.Error(err)
+	assert.NotEqual(errNoEditor, err)
+
+	// An editor that can't be found is reported, so that the text can be
+	// entered without it
+	_, err = editText("gitmoji-no-such-editor", "Enter the commit body", false, "")
+	assert.Equal(errNoEditor, err)
+}
+
+func TestCleanupText(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("", cleanupText("\n# comment\n\n", true))
+	assert.Equal("# heading\n\ntext", cleanupText("# heading\n\n\ntext\n", false))
+}
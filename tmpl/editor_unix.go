@@ -0,0 +1,16 @@
+//go:build !windows
+
+package tmpl
+
+import "os/exec"
+
+// commandNotFound is the exit status of the shell when it can't find the
+// command to run.
+const commandNotFound = 127
+
+// editorProcess returns the command that runs the editor on the file. Like
+// git, it runs the editor with the shell, so that it may have arguments.
+func editorProcess(editor string, file string) *exec.Cmd {
+	// #nosec G204
+	return exec.Command("sh", "-c", editor+` "$@"`, editor, file)
+}
@@ -0,0 +1,22 @@
+package tmpl
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// commandNotFound is the exit status of cmd.exe when it can't find the command
+// to run.
+const commandNotFound = 9009
+
+// editorProcess returns the command that runs the editor on the file. It runs
+// the editor with cmd.exe, so that it may have arguments.
+func editorProcess(editor string, file string) *exec.Cmd {
+	cmd := exec.Command("cmd.exe")
+	// /S keeps the quotes within the command, e.g. around the file name
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine: `cmd.exe /S /C "` + editor + ` "` + file + `""`,
+	}
+
+	return cmd
+}
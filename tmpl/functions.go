@@ -0,0 +1,55 @@
+package tmpl
+
+import (
+	"regexp"
+
+	"github.com/spf13/viper"
+
+	"github.com/jamesdobson/gogitmoji/git"
+)
+
+// templateFunctions are the functions available to the templates for command
+// arguments, messages and prompt defaults.
+var templateFunctions = map[string]interface{}{
+	"getString":   viper.GetString,
+	"join":        join,
+	"branch":      branch,
+	"lastSubject": lastSubject,
+	"lastScope":   lastScope,
+}
+
+// scopePattern finds the scope in a commit subject like "feat(api): ..." or
+// "✨ (api): ...".
+var scopePattern = regexp.MustCompile(`\(([^()]+)\)!?:`)
+
+// branch returns the name of the current branch, or the empty string if
+// there isn't one.
+func branch() string {
+	name, _ := git.CurrentBranch()
+
+	return name
+}
+
+// lastSubject returns the subject of the previous commit, or the empty string
+// if there isn't one.
+func lastSubject() string {
+	subject, _ := git.LastSubject()
+
+	return subject
+}
+
+// lastScope returns the scope of the previous commit, or the empty string if
+// it didn't have one.
+func lastScope() string {
+	return scopeOf(lastSubject())
+}
+
+func scopeOf(subject string) string {
+	match := scopePattern.FindStringSubmatch(subject)
+
+	if match == nil {
+		return ""
+	}
+
+	return match[1]
+}
@@ -0,0 +1,55 @@
+package tmpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopeOf(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("api", scopeOf("feat(api): add pagination"))
+	assert.Equal("api", scopeOf("feat(api)!: drop v1"))
+	assert.Equal("cache", scopeOf("🐛  (cache): fix the lock (again): really"))
+	assert.Equal("", scopeOf("fix: stop crashing (on empty input)"))
+	assert.Equal("", scopeOf(""))
+}
+
+func TestDefaults(t *testing.T) {
+	assert := assert.New(t)
+	tpl := CommandTemplate{
+		Prompts: []Prompt{
+			{Type: "text", Name: "type", Mandatory: true, Default: "fix"},
+			{Type: "text", Name: "title", Mandatory: true},
+			{Type: "text", Name: "scope", Default: "{{if eq .type \"docs\"}}readme{{end}}"},
+			{Type: "confirm", Name: "breaking", Default: "{{if eq .type \"feat\"}}yes{{end}}"},
+			{Type: "multichoice", Name: "components", Default: "api, cli",
+				Choices: []PromptChoice{{Value: "api"}, {Value: "cli"}, {Value: "docs"}}},
+		},
+	}
+
+	answers := getAnswers(tpl, Options{
+		Answers: map[string]interface{}{"title": "stop crashing"},
+		NoInput: true,
+	})
+
+	assert.Equal("fix", answers["type"])
+	assert.Equal("", answers["scope"])
+	assert.Equal(false, answers["breaking"])
+	assert.Equal([]string{"api", "cli"}, answers["components"])
+
+	answers = getAnswers(tpl, Options{
+		Answers: map[string]interface{}{"type": "docs", "title": "explain scopes"},
+		NoInput: true,
+	})
+
+	assert.Equal("readme", answers["scope"])
+
+	answers = getAnswers(tpl, Options{
+		Answers: map[string]interface{}{"type": "feat", "title": "add scopes"},
+		NoInput: true,
+	})
+
+	assert.Equal(true, answers["breaking"])
+}
@@ -9,10 +9,10 @@ var gitmojiCommandTemplate = CommandTemplate{
 			Name:      "gitmoji",
 		},
 		{
-			Type:      "text",
+			Type:      "scope",
 			Mandatory: false,
 			Prompt:    "Enter the scope of current changes",
-			Name:      "Scope",
+			Name:      "scope",
 			Condition: "scope",
 		},
 		{
@@ -0,0 +1,61 @@
+package tmpl
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/viper"
+
+	"github.com/jamesdobson/gogitmoji/git"
+)
+
+// IssuePatternSetting is the setting holding the regular expression that
+// finds the issue key in the name of the current branch. If the expression
+// has a group, the key is the text matched by the first group; otherwise it
+// is the text matched by the whole expression.
+const IssuePatternSetting = "issue.pattern"
+
+// DefaultIssuePattern matches issue keys like PROJ-123.
+const DefaultIssuePattern = `[A-Z][A-Z0-9]+-[0-9]+`
+
+// branchIssue returns the issue key in the name of the current branch, or the
+// empty string if there isn't one.
+func branchIssue() (string, error) {
+	branch, err := git.CurrentBranch()
+
+	if err != nil {
+		// Not on a branch, or not in a git repository
+		return "", nil
+	}
+
+	pattern := DefaultIssuePattern
+
+	if viper.IsSet(IssuePatternSetting) {
+		pattern = viper.GetString(IssuePatternSetting)
+	}
+
+	return findIssue(branch, pattern)
+}
+
+func findIssue(branch string, pattern string) (string, error) {
+	if pattern == "" {
+		return "", nil
+	}
+
+	re, err := regexp.Compile(pattern)
+
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %v", IssuePatternSetting, err)
+	}
+
+	match := re.FindStringSubmatch(branch)
+
+	switch {
+	case match == nil:
+		return "", nil
+	case len(match) > 1:
+		return match[1], nil
+	default:
+		return match[0], nil
+	}
+}
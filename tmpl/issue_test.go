@@ -0,0 +1,53 @@
+package tmpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindIssue(t *testing.T) {
+	assert := assert.New(t)
+
+	for branch, expected := range map[string]string{
+		"feature/PROJ-123-foo": "PROJ-123",
+		"PROJ-7":               "PROJ-7",
+		"bugfix/ab2-99-lower":  "",
+		"main":                 "",
+	} {
+		issue, err := findIssue(branch, DefaultIssuePattern)
+		assert.NoError(err)
+		assert.Equal(expected, issue, branch)
+	}
+
+	issue, err := findIssue("fix/issue-42-crash", `issue-([0-9]+)`)
+	assert.NoError(err)
+	assert.Equal("42", issue)
+
+	issue, err = findIssue("feature/PROJ-123-foo", "")
+	assert.NoError(err)
+	assert.Equal("", issue)
+
+	_, err = findIssue("main", `(`)
+	assert.Error(err)
+}
+
+func TestConventionalIssueFooter(t *testing.T) {
+	assert := assert.New(t)
+	messages := conventionalCommandTemplate.Messages
+	answers := map[string]interface{}{
+		"type":        "fix",
+		"description": "stop crashing",
+		"body":        "",
+		"footer":      "",
+		"issue":       "PROJ-123",
+	}
+
+	assert.Equal([]string{"fix: stop crashing", "Refs: PROJ-123"}, generateArgs(&messages, answers))
+
+	answers["footer"] = "Reviewed-by: Z"
+	assert.Equal([]string{"fix: stop crashing", "Reviewed-by: Z\nRefs: PROJ-123"}, generateArgs(&messages, answers))
+
+	answers["issue"] = ""
+	assert.Equal([]string{"fix: stop crashing", "Reviewed-by: Z"}, generateArgs(&messages, answers))
+}
@@ -0,0 +1,192 @@
+package tmpl
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+
+	"github.com/jamesdobson/gogitmoji/i18n"
+)
+
+// multiChoiceItem is an item in the list shown by promptMultiChoice: either a
+// choice that can be ticked, or the item that finishes the selection.
+type multiChoiceItem struct {
+	PromptChoice
+	Ticked bool
+	Done   bool
+}
+
+// promptMultiChoice asks the user to tick any number of the prompt's choices,
+// between its Min and Max, and returns the values of the ticked choices in
+// the order of the choices. The choices whose values are in the default, which
+// is separated by commas, start ticked.
+func promptMultiChoice(question Prompt, defaultValue string) []string {
+	label := i18n.T(question.Prompt)
+	items := []*multiChoiceItem{{
+		PromptChoice: PromptChoice{Value: i18n.T("Done")},
+		Done:         true,
+	}}
+
+	ticked := map[string]bool{}
+
+	for _, value := range strings.Split(defaultValue, ",") {
+		ticked[strings.TrimSpace(value)] = true
+	}
+
+	for _, choice := range question.Choices {
+		items = append(items, &multiChoiceItem{
+			PromptChoice: PromptChoice{
+				Value:       choice.Value,
+				Description: i18n.T(choice.Description),
+			},
+			Ticked: ticked[choice.Value],
+		})
+	}
+
+	templates := &promptui.SelectTemplates{
+		Label: "{{ \"?\" | yellow }} {{ . }}",
+		Active: `‣ {{ if .Done }}{{ .Value | bold }}{{ else }}{{ if .Ticked }}{{ "◉" | green }}{{ else }}◯{{ end }} ` +
+			`{{ .Value }} 	{{ .Description }}{{ end }}`,
+		Inactive: `  {{ if .Done }}{{ .Value | bold }}{{ else }}{{ if .Ticked }}{{ "◉" | green }}{{ else }}◯{{ end }} ` +
+			`{{ .Value }} 	{{ .Description }}{{ end }}`,
+	}
+
+	searcher := func(input string, index int) bool {
+		t := items[index]
+		tosearch := t.Value + t.Description
+
+		// Normalize
+		tosearch = strings.ReplaceAll(strings.ToLower(tosearch), " ", "")
+		input = strings.ReplaceAll(strings.ToLower(input), " ", "")
+
+		return t.Done || strings.Contains(tosearch, input)
+	}
+
+	prompt := promptui.Select{
+		Label:        label,
+		Items:        items,
+		Templates:    templates,
+		Size:         12,
+		Searcher:     searcher,
+		HideSelected: true,
+		Stdout:       console,
+	}
+
+	cursor, scroll := 1, 0
+
+	for {
+		i, _, err := prompt.RunCursorAt(cursor, scroll)
+
+		if err != nil {
+			if err == promptui.ErrInterrupt {
+				fmt.Fprintln(console, i18n.T("Canceled."))
+				os.Exit(1)
+			}
+
+			log.Panic(err)
+		}
+
+		if !items[i].Done {
+			items[i].Ticked = !items[i].Ticked
+			cursor, scroll = i, prompt.ScrollPosition()
+			continue
+		}
+
+		var values []string
+
+		for n, item := range items {
+			if item.Ticked {
+				values = append(values, question.Choices[n-1].Value)
+			}
+		}
+
+		if err := checkSelectionCount(question, len(values)); err != nil {
+			fmt.Fprintf(console, "%s %v\n", promptui.IconBad, err)
+			cursor, scroll = 0, 0
+			continue
+		}
+
+		fmt.Fprintf(console, "%s %s\n", promptui.Styler(promptui.FGFaint)("? "+label), strings.Join(values, ", "))
+
+		return values
+	}
+}
+
+// checkSelectionCount returns an error if the number of choices selected in a
+// multichoice prompt is not allowed by its Min, Max and Mandatory fields.
+func checkSelectionCount(question Prompt, count int) error {
+	least := question.Min
+
+	if question.Mandatory && least < 1 {
+		least = 1
+	}
+
+	if count < least {
+		return fmt.Errorf(i18n.T("choose at least %d"), least)
+	}
+
+	if question.Max > 0 && count > question.Max {
+		return fmt.Errorf(i18n.T("choose at most %d"), question.Max)
+	}
+
+	return nil
+}
+
+// join joins the items of a list answer with a separator. It accepts the
+// lists read from answer files as well as those from multichoice prompts.
+func join(list interface{}, sep string) string {
+	switch list := list.(type) {
+	case []string:
+		return strings.Join(list, sep)
+	case []interface{}:
+		values := make([]string, len(list))
+
+		for i, value := range list {
+			values[i] = fmt.Sprint(value)
+		}
+
+		return strings.Join(values, sep)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(list)
+	}
+}
+
+// parseMultiChoice converts an answer given in advance to the answer of a
+// multichoice prompt: either a list, or a string of comma-separated values.
+func parseMultiChoice(question Prompt, value interface{}) ([]string, error) {
+	var values []string
+
+	switch value := value.(type) {
+	case []interface{}:
+		for _, v := range value {
+			values = append(values, fmt.Sprint(v))
+		}
+	default:
+		for _, v := range strings.Split(fmt.Sprint(value), ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+
+	for _, v := range values {
+		if err := checkChoice(question, v); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkSelectionCount(question, len(values)); err != nil {
+		return nil, err
+	}
+
+	if values == nil {
+		values = []string{}
+	}
+
+	return values, nil
+}
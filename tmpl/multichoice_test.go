@@ -0,0 +1,57 @@
+package tmpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var componentsPrompt = Prompt{
+	Type: "multichoice",
+	Name: "components",
+	Choices: []PromptChoice{
+		{Value: "api"},
+		{Value: "cli"},
+		{Value: "docs"},
+	},
+	Max: 2,
+}
+
+func TestParseMultiChoice(t *testing.T) {
+	assert := assert.New(t)
+
+	values, err := parseMultiChoice(componentsPrompt, "api, docs")
+	assert.NoError(err)
+	assert.Equal([]string{"api", "docs"}, values)
+
+	values, err = parseMultiChoice(componentsPrompt, []interface{}{"cli"})
+	assert.NoError(err)
+	assert.Equal([]string{"cli"}, values)
+
+	values, err = parseMultiChoice(componentsPrompt, "")
+	assert.NoError(err)
+	assert.Equal([]string{}, values)
+
+	_, err = parseMultiChoice(componentsPrompt, "api,web")
+	assert.Error(err)
+
+	_, err = parseMultiChoice(componentsPrompt, "api,cli,docs")
+	assert.Error(err)
+
+	mandatory := componentsPrompt
+	mandatory.Mandatory = true
+	_, err = parseMultiChoice(mandatory, "")
+	assert.Error(err)
+}
+
+func TestJoin(t *testing.T) {
+	assert := assert.New(t)
+	messages := []string{"Components: {{join .components \", \"}}"}
+
+	assert.Equal([]string{"Components: api, cli"},
+		generateArgs(&messages, map[string]interface{}{"components": []string{"api", "cli"}}))
+	assert.Equal([]string{"Components: api"},
+		generateArgs(&messages, map[string]interface{}{"components": []interface{}{"api"}}))
+	assert.Equal([]string{"Components: "},
+		generateArgs(&messages, map[string]interface{}{}))
+}
@@ -0,0 +1,89 @@
+package tmpl
+
+import (
+	"github.com/jamesdobson/gogitmoji/gitmoji"
+)
+
+// gitmojiRanking shows the gitmoji in a promptui.Select ranked by how well
+// they match the search input.
+//
+// promptui can only filter a select's items, keeping them in their original
+// order. So the select's items are pointers to slots, and each time the
+// search input changes, the gitmoji are rearranged in the slots: matches
+// first, best first, followed by the rest of the gitmoji. The searcher then
+// reports the first slots, one for each match, as matching.
+type gitmojiRanking struct {
+	gitmoji []gitmoji.Gitmoji
+	slots   []*gitmoji.Gitmoji
+	order   []int
+	matches int
+	input   string
+}
+
+func newGitmojiRanking(list []gitmoji.Gitmoji) *gitmojiRanking {
+	r := &gitmojiRanking{
+		gitmoji: list,
+		slots:   make([]*gitmoji.Gitmoji, len(list)),
+		order:   make([]int, len(list)),
+		matches: len(list),
+	}
+
+	for i := range list {
+		r.slots[i] = &gitmoji.Gitmoji{}
+	}
+
+	r.arrange(nil)
+
+	return r
+}
+
+// searcher implements list.Searcher. promptui calls it for each slot in turn,
+// starting with the first, whenever the search input changes.
+func (r *gitmojiRanking) searcher(input string, index int) bool {
+	if index == 0 && input != r.input {
+		r.input = input
+		r.arrange(gitmoji.Search(r.gitmoji, input))
+	}
+
+	return index < r.matches
+}
+
+// selected returns the gitmoji in the slot at the given index.
+func (r *gitmojiRanking) selected(index int) gitmoji.Gitmoji {
+	return r.gitmoji[r.order[index]]
+}
+
+// arrange fills the slots with the matches, followed by the other gitmoji in
+// their original order. If matches is nil, every gitmoji matches.
+func (r *gitmojiRanking) arrange(matches []gitmoji.Gitmoji) {
+	used := make([]bool, len(r.gitmoji))
+	slot := 0
+
+	place := func(i int) {
+		used[i] = true
+		r.order[slot] = i
+		*r.slots[slot] = r.gitmoji[i]
+		slot++
+	}
+
+	for _, m := range matches {
+		for i, g := range r.gitmoji {
+			if !used[i] && g.Code == m.Code {
+				place(i)
+				break
+			}
+		}
+	}
+
+	r.matches = slot
+
+	for i := range r.gitmoji {
+		if !used[i] {
+			place(i)
+		}
+	}
+
+	if matches == nil {
+		r.matches = len(r.gitmoji)
+	}
+}
@@ -2,14 +2,20 @@ package tmpl
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/jamesdobson/gogitmoji/gitmoji"
+	"github.com/jamesdobson/gogitmoji/history"
+	"github.com/jamesdobson/gogitmoji/i18n"
+	"github.com/jamesdobson/gogitmoji/suggest"
 	"github.com/manifoldco/promptui"
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
@@ -32,6 +38,15 @@ type Prompt struct {
 	Name      string         `yaml:"Name"`
 	Condition string         `yaml:"Condition,omitempty"`
 	Choices   []PromptChoice `yaml:"Choices,omitempty"`
+	Default   string         `yaml:"Default,omitempty"`
+	Min       int            `yaml:"Min,omitempty"`
+	Max       int            `yaml:"Max,omitempty"`
+
+	// Rules for the answers to text prompts
+	MinLength      int    `yaml:"MinLength,omitempty"`
+	MaxLength      int    `yaml:"MaxLength,omitempty"`
+	Pattern        string `yaml:"Pattern,omitempty"`
+	PatternMessage string `yaml:"PatternMessage,omitempty"`
 }
 
 // PromptChoice defines a single option in a multiple-choice prompt.
@@ -58,60 +73,188 @@ func LoadTemplates(templates map[string]interface{}) {
 			log.Fatalf("Error processing template '%s': %v", name, err)
 		}
 
+		for _, question := range result.Prompts {
+			if _, err := regexp.Compile(question.Pattern); err != nil {
+				log.Fatalf("Error processing template '%s': invalid pattern for '%s': %v", name, question.Name, err)
+			}
+		}
+
 		TemplateLookup[name] = result
 	}
 }
 
+// console is where prompts and other messages for the user are written. When
+// the result is printed on standard output, they are written to standard
+// error instead, so that the result can be piped into another command.
+var console io.WriteCloser = os.Stdout
+
 // RunTemplateCommand prompts the user for the template prompts and then runs
-// the command specified in the template.
-func RunTemplateCommand(tpl CommandTemplate) {
-	answers := getAnswers(tpl)
-	args := generateArgs(&(tpl.CommandArgs), answers)
+// the command specified in the template, followed by opts.ExtraArgs. With
+// opts.DryRun, the command is shown but not run; with opts.Print, the
+// template's messages are printed on standard output instead.
+func RunTemplateCommand(tpl CommandTemplate, opts Options) {
+	if opts.Print {
+		console = os.Stderr
+	}
+
+	answers := getAnswers(tpl, opts)
+
+	if opts.Print {
+		messages := generateArgs(&(tpl.Messages), answers)
+		fmt.Println(strings.Join(messages, "\n\n"))
+		return
+	}
+
+	args := append(generateArgs(&(tpl.CommandArgs), answers), opts.ExtraArgs...)
 	displayCommand := getPrintableCommand(tpl.Command, args)
-	fmt.Printf("Going to execute: %s\n\n", displayCommand)
+	fmt.Fprintf(console, "%s %s\n\n", i18n.T("Going to execute:"), displayCommand)
+
+	if opts.DryRun {
+		return
+	}
 
-	if !confirm("Execute") {
-		fmt.Printf("Canceled.\n")
+	if !opts.NoInput && !confirm(i18n.T("Execute")) {
+		fmt.Fprintf(console, "%s\n", i18n.T("Canceled."))
 		return
 	}
 
 	run(tpl.Command, args)
+	recordGitmoji(tpl, answers)
 }
 
 // GetTemplateMessage prompts the user for the teamplate prompts and then
 // returns the result of formatting the "Messages" into a string.
-func GetTemplateMessage(tpl CommandTemplate) string {
-	answers := getAnswers(tpl)
+func GetTemplateMessage(tpl CommandTemplate, opts Options) string {
+	answers := getAnswers(tpl, opts)
 	messages := generateArgs(&(tpl.Messages), answers)
+	recordGitmoji(tpl, answers)
 
 	return strings.Join(messages, "\n\n")
 }
 
-func getAnswers(tpl CommandTemplate) map[string]interface{} {
+// recordGitmoji adds the gitmoji in the answers to the template's gitmoji
+// prompts, whether chosen or given in advance, to the history of chosen
+// gitmoji.
+func recordGitmoji(tpl CommandTemplate, answers map[string]interface{}) {
+	var codes []string
+
+	for _, question := range tpl.Prompts {
+		if g, ok := answers[question.Name].(gitmoji.Gitmoji); ok && question.Type == "gitmoji" && g.Code != "" {
+			codes = append(codes, g.Code)
+		}
+	}
+
+	if len(codes) == 0 {
+		return
+	}
+
+	hist, err := history.New()
+
+	if err != nil {
+		fmt.Fprintf(console, "⚠️  %v\n", err)
+		return
+	}
+
+	for _, code := range codes {
+		if err := hist.Record(code, time.Now()); err != nil {
+			fmt.Fprintf(console, "⚠️  %v\n", err)
+		}
+	}
+}
+
+func getAnswers(tpl CommandTemplate, opts Options) map[string]interface{} {
 	var answers = map[string]interface{}{}
 
+	promptui.SearchPrompt = i18n.T("Search: ")
+
+	// The issue key from the branch name is available to every template, but
+	// can be replaced by a prompt with the same name.
+	issue, err := branchIssue()
+
+	if err != nil {
+		log.Fatalf("Unable to find the issue key in the branch name: %v", err)
+	}
+
+	answers[issueAnswer] = issue
+
+	if err := checkAnswerNames(tpl, opts.Answers); err != nil {
+		log.Fatalf("Invalid answers: %v", err)
+	}
+
+	if value, ok := opts.Answers[issueAnswer]; ok {
+		answers[issueAnswer] = fmt.Sprint(value)
+	}
+
+	// With answers given in advance, only the unanswered mandatory prompts
+	// are asked.
+	preset := opts.NoInput || len(opts.Answers) > 0
+
 	for q := 0; q < len(tpl.Prompts); q++ {
 		var question = tpl.Prompts[q]
 
+		// An answer given in advance turns on a prompt whose condition is off,
+		// e.g. an answer for the scope without --scope
+		if value, ok := opts.Answers[question.Name]; ok {
+			answer, err := presetAnswer(question, value)
+
+			if err != nil {
+				log.Fatalf("Invalid answer for '%s': %v", question.Name, err)
+			}
+
+			answers[question.Name] = answer
+			continue
+		}
+
 		if question.Condition != "" && !viper.GetBool(question.Condition) {
 			continue
 		}
 
+		defaultValue, err := renderTemplate(question.Default, answers)
+
+		if err != nil {
+			log.Fatalf("Invalid default for '%s': %v", question.Name, err)
+		}
+
+		// Unasked prompts get their default answer
+		if preset && (!question.Mandatory || opts.NoInput) && defaultValue != "" {
+			answer, err := presetAnswer(question, defaultValue)
+
+			if err != nil {
+				log.Fatalf("Invalid default for '%s': %v", question.Name, err)
+			}
+
+			answers[question.Name] = answer
+			continue
+		}
+
+		if preset && !question.Mandatory {
+			answers[question.Name] = emptyAnswer(question)
+			continue
+		}
+
+		if opts.NoInput {
+			log.Fatalf("No answer for '%s', which is required", question.Name)
+		}
+
 		switch question.Type {
 		case "text":
-			answer := promptOrCancel(question.Prompt, question.Mandatory)
+			answer := promptOrCancel(question, defaultValue)
+			answers[question.Name] = answer
+
+		case "scope":
+			answer := promptScope(question, defaultValue)
 			answers[question.Name] = answer
 
 		case "choice":
-			answer := promptChoice(question)
+			answer := promptChoice(question, defaultValue)
 			answers[question.Name] = answer
 
 		case "gitmoji":
-			gitmoji, err := promptGitmoji()
+			gitmoji, err := promptGitmoji(defaultValue)
 
 			if err != nil {
 				if err == promptui.ErrInterrupt {
-					fmt.Println("Canceled.")
+					fmt.Fprintln(console, i18n.T("Canceled."))
 					os.Exit(1)
 				}
 
@@ -120,6 +263,18 @@ func getAnswers(tpl CommandTemplate) map[string]interface{} {
 
 			answers[question.Name] = gitmoji
 
+		case "confirm":
+			answer := promptConfirm(question, defaultValue)
+			answers[question.Name] = answer
+
+		case "editor":
+			answer := promptEditor(question, defaultValue)
+			answers[question.Name] = answer
+
+		case "multichoice":
+			answer := promptMultiChoice(question, defaultValue)
+			answers[question.Name] = answer
+
 		default:
 			log.Fatalf("Unknown prompt type '%s'...\n", question.Type)
 		}
@@ -130,29 +285,14 @@ func getAnswers(tpl CommandTemplate) map[string]interface{} {
 
 func generateArgs(templates *[]string, answers map[string]interface{}) []string {
 	var args = make([]string, 0, len(*templates))
-	var sb strings.Builder
-	var functions = map[string]interface{}{
-		"getString": viper.GetString,
-	}
 
 	for n := 0; n < len(*templates); n++ {
-		sb.Reset()
-		t, err := template.New("arg").
-			Funcs(functions).
-			Parse((*templates)[n])
-
-		if err != nil {
-			panic(err)
-		}
-
-		err = t.Execute(&sb, answers)
+		arg, err := renderTemplate((*templates)[n], answers)
 
 		if err != nil {
 			panic(err)
 		}
 
-		arg := sb.String()
-
 		if arg != "" {
 			args = append(args, arg)
 		}
@@ -161,6 +301,27 @@ func generateArgs(templates *[]string, answers map[string]interface{}) []string
 	return args
 }
 
+// renderTemplate executes a Go template with the answers given so far.
+func renderTemplate(text string, answers map[string]interface{}) (string, error) {
+	var sb strings.Builder
+
+	t, err := template.New("arg").
+		Funcs(templateFunctions).
+		Parse(text)
+
+	if err != nil {
+		return "", err
+	}
+
+	err = t.Execute(&sb, answers)
+
+	if err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
 func getPrintableCommand(name string, args []string) string {
 	var sb = &strings.Builder{}
 
@@ -180,7 +341,7 @@ func getPrintableCommand(name string, args []string) string {
 }
 
 func run(name string, args []string) {
-	fmt.Printf("Executing...\n")
+	fmt.Fprintf(console, "%s\n", i18n.T("Executing..."))
 
 	var cmd = exec.Command(name, args...)
 
@@ -191,7 +352,7 @@ func run(name string, args []string) {
 
 	if err != nil {
 		code := cmd.ProcessState.ExitCode()
-		fmt.Printf("\n'%s' exited with code: %d\n", name, code)
+		fmt.Fprintf(console, "\n'%s' exited with code: %d\n", name, code)
 		os.Exit(code)
 	}
 }
@@ -200,6 +361,7 @@ func confirm(question string) bool {
 	prompt := promptui.Prompt{
 		Label:     question,
 		IsConfirm: true,
+		Stdout:    console,
 	}
 
 	result, err := prompt.Run()
@@ -207,12 +369,49 @@ func confirm(question string) bool {
 	return err == nil && strings.ToLower(result) == "y"
 }
 
-func promptOrCancel(question string, mandatory bool) string {
-	s, err := prompt(question, mandatory)
+// promptConfirm asks a yes or no question. The answer is the default if the
+// user just presses enter; if the default is empty, that means no.
+func promptConfirm(question Prompt, defaultValue string) bool {
+	prompt := promptui.Prompt{
+		Label:     i18n.T(question.Prompt),
+		IsConfirm: true,
+		Stdout:    console,
+	}
+
+	if defaultValue != "" {
+		yes, err := parseConfirm(defaultValue)
+
+		if err != nil {
+			log.Fatalf("Invalid default for '%s': %v", question.Name, err)
+		}
+
+		if yes {
+			prompt.Default = "y"
+		}
+	}
+
+	_, err := prompt.Run()
+
+	if err != nil {
+		if err == promptui.ErrInterrupt {
+			fmt.Fprintln(console, i18n.T("Canceled."))
+			os.Exit(1)
+		}
+
+		if err != promptui.ErrAbort {
+			log.Panic(err)
+		}
+	}
+
+	return err == nil
+}
+
+func promptOrCancel(question Prompt, defaultValue string) string {
+	s, err := prompt(question, defaultValue)
 
 	if err != nil {
 		if err == promptui.ErrInterrupt {
-			fmt.Println("Canceled.")
+			fmt.Fprintln(console, i18n.T("Canceled."))
 			os.Exit(1)
 		}
 
@@ -222,23 +421,22 @@ func promptOrCancel(question string, mandatory bool) string {
 	return s
 }
 
-func prompt(question string, mandatory bool) (string, error) {
+func prompt(question Prompt, defaultValue string) (string, error) {
 	templates := &promptui.PromptTemplates{
 		Success: `{{ "✔" | faint }} {{ . | faint }}{{ ":" | faint }} `,
 	}
 
 	validator := func(input string) error {
-		if !mandatory || len(input) >= 1 {
-			return nil
-		}
-
-		return fmt.Errorf("this is required")
+		return validateAnswer(question, input)
 	}
 
 	prompt := promptui.Prompt{
-		Label:     question,
+		Label:     i18n.T(question.Prompt),
+		Default:   defaultValue,
+		AllowEdit: true,
 		Validate:  validator,
 		Templates: templates,
+		Stdout:    console,
 
 		// Disable the pointer
 		//Pointer: func(x []rune) []rune { return x },
@@ -253,7 +451,10 @@ func prompt(question string, mandatory bool) (string, error) {
 	return result, nil
 }
 
-func promptGitmoji() (gitmoji.Gitmoji, error) {
+// promptGitmoji asks the user to choose a gitmoji. The gitmoji with the code
+// given by the default comes first, followed by the gitmoji suggested for the
+// staged changes, and then the rest, with the ones chosen most often first.
+func promptGitmoji(defaultCode string) (gitmoji.Gitmoji, error) {
 	cache, err := gitmoji.NewCache()
 
 	if err != nil {
@@ -266,57 +467,118 @@ func promptGitmoji() (gitmoji.Gitmoji, error) {
 		log.Fatal("Unable to get list of gitmoji: ", err)
 	}
 
+	hist, err := history.New()
+
+	if err != nil {
+		fmt.Fprintf(console, "⚠️  %v\n", err)
+	} else {
+		glist = hist.Sort(glist, time.Now())
+	}
+
+	suggestions, err := suggest.Staged()
+
+	if err != nil {
+		fmt.Fprintf(console, "⚠️  %v\n", err)
+	}
+
+	if defaultCode != "" {
+		suggestions = append([]string{defaultCode}, suggestions...)
+	}
+
+	glist = moveToFront(glist, suggestions)
+	label := i18n.T("Choose a gitmoji")
+
 	templates := &promptui.SelectTemplates{
 		Label:    "{{ \"?\" | yellow }} {{ . }}",
 		Active:   "‣ {{ .Emoji }}  - {{ .Code | cyan }} - {{ .Description }}",
 		Inactive: "  {{ .Emoji }}  - {{ .Code | cyan }} - {{ .Description }}",
-		Selected: `{{ "? Choose a gitmoji" | faint }} {{ .Emoji }}  - {{ .Description }}`,
+		Selected: `{{ ` + strconv.Quote("? "+label) + ` | faint }} {{ .Emoji }}  - {{ .Description }}`,
 		Details: `
 --------- Gitmoji ----------
-{{ "Name:" | faint }}	{{ .Emoji }} {{ .Name }}
-{{ "Entity:" | faint }}	{{ .Entity }}
-{{ "Code:" | faint }}	{{ .Code }}
-{{ "Description:" | faint }}	{{ .Description }}`,
+{{ ` + strconv.Quote(i18n.T("Name:")) + ` | faint }}	{{ .Emoji }} {{ .Name }}
+{{ ` + strconv.Quote(i18n.T("Entity:")) + ` | faint }}	{{ .Entity }}
+{{ ` + strconv.Quote(i18n.T("Code:")) + ` | faint }}	{{ .Code }}
+{{ ` + strconv.Quote(i18n.T("Description:")) + ` | faint }}	{{ .Description }}
+{{ ` + strconv.Quote(i18n.T("Semver:")) + ` | faint }}	{{ with .Semver }}{{ . }}{{ else }}` + i18n.T("none") + `{{ end }}`,
 	}
 
-	searcher := func(input string, index int) bool {
-		gitmoji := glist[index]
-		tosearch := gitmoji.Name + gitmoji.Code + gitmoji.Description
-
-		// Normalize
-		tosearch = strings.ReplaceAll(strings.ToLower(tosearch), " ", "")
-		input = strings.ReplaceAll(strings.ToLower(input), " ", "")
-
-		return strings.Contains(tosearch, input)
-	}
+	ranking := newGitmojiRanking(glist)
 
 	prompt := promptui.Select{
-		Label:     "Choose a gitmoji",
-		Items:     glist,
+		Label:     label,
+		Items:     ranking.slots,
 		Templates: templates,
 		Size:      12,
-		Searcher:  searcher,
+		Searcher:  ranking.searcher,
+		Stdout:    console,
 	}
 
 	i, _, err := prompt.Run()
 
+	// Wait even if the prompt was canceled, so that the refresh isn't cut
+	// short with the cache locked
+	if err := cache.WaitForRefresh(); err != nil {
+		fmt.Fprintf(console, "⚠️  %v\n", err)
+	}
+
 	if err != nil {
 		return gitmoji.Gitmoji{}, err
 	}
 
-	return glist[i], nil
+	return ranking.selected(i), nil
 }
 
-func promptChoice(question Prompt) string {
+// moveToFront returns the list of gitmoji with the ones with the given codes
+// moved to the front, in the order of the codes.
+func moveToFront(list []gitmoji.Gitmoji, codes []string) []gitmoji.Gitmoji {
+	result := make([]gitmoji.Gitmoji, 0, len(list))
+	moved := make(map[string]bool, len(codes))
+
+	for _, code := range codes {
+		for _, g := range list {
+			if g.Code == code && !moved[code] {
+				result = append(result, g)
+				moved[code] = true
+			}
+		}
+	}
+
+	for _, g := range list {
+		if !moved[g.Code] {
+			result = append(result, g)
+		}
+	}
+
+	return result
+}
+
+// promptChoice asks the user to choose one of the prompt's choices, starting
+// with the one whose value is the default.
+func promptChoice(question Prompt, defaultValue string) string {
+	label := i18n.T(question.Prompt)
+	choices := make([]PromptChoice, len(question.Choices))
+	cursor := 0
+
+	for i, choice := range question.Choices {
+		choices[i] = PromptChoice{
+			Value:       choice.Value,
+			Description: i18n.T(choice.Description),
+		}
+
+		if choice.Value == defaultValue {
+			cursor = i
+		}
+	}
+
 	templates := &promptui.SelectTemplates{
 		Label:    "{{ \"?\" | yellow }} {{ . }}",
 		Active:   "‣ {{ .Value }} 	{{ .Description }}",
 		Inactive: "  {{ .Value }} 	{{ .Description }}",
-		Selected: `{{ "? ` + question.Prompt + `" | faint }} {{ .Value }}  - {{ .Description }}`,
+		Selected: `{{ ` + strconv.Quote("? "+label) + ` | faint }} {{ .Value }}  - {{ .Description }}`,
 	}
 
 	searcher := func(input string, index int) bool {
-		t := question.Choices[index]
+		t := choices[index]
 		tosearch := t.Value + t.Description
 
 		// Normalize
@@ -327,18 +589,20 @@ func promptChoice(question Prompt) string {
 	}
 
 	prompt := promptui.Select{
-		Label:     question.Prompt,
-		Items:     question.Choices,
+		Label:     label,
+		Items:     choices,
 		Templates: templates,
 		Size:      12,
 		Searcher:  searcher,
+		CursorPos: cursor,
+		Stdout:    console,
 	}
 
 	i, _, err := prompt.Run()
 
 	if err != nil {
 		if err == promptui.ErrInterrupt {
-			fmt.Println("Canceled.")
+			fmt.Fprintln(console, i18n.T("Canceled."))
 			os.Exit(1)
 		}
 
@@ -347,3 +611,74 @@ func promptChoice(question Prompt) string {
 
 	return question.Choices[i].Value
 }
+
+// promptScope asks for the scope of the changes, suggesting the scopes of the
+// staged files. A single suggestion is offered as the default answer; several
+// are offered as a list to choose from. The prompt's default is only used when
+// nothing is suggested, or to choose where the list starts.
+func promptScope(question Prompt, defaultValue string) string {
+	label := i18n.T(question.Prompt)
+	scopes, err := suggest.StagedScopes()
+
+	if err != nil {
+		fmt.Fprintf(console, "⚠️  %v\n", err)
+	}
+
+	switch len(scopes) {
+	case 0:
+		return promptOrCancel(question, defaultValue)
+	case 1:
+		return promptOrCancel(question, scopes[0])
+	}
+
+	cursor := 0
+
+	for i, scope := range scopes {
+		if scope == defaultValue {
+			cursor = i
+		}
+	}
+
+	other := len(scopes)
+	items := append(append([]string{}, scopes...), i18n.T("Other..."))
+
+	if !question.Mandatory {
+		items = append(items, i18n.T("None"))
+	}
+
+	templates := &promptui.SelectTemplates{
+		Label:    "{{ \"?\" | yellow }} {{ . }}",
+		Active:   "‣ {{ . }}",
+		Inactive: "  {{ . }}",
+		Selected: `{{ ` + strconv.Quote("? "+label) + ` | faint }} {{ . }}`,
+	}
+
+	prompt := promptui.Select{
+		Label:     label,
+		Items:     items,
+		Templates: templates,
+		Size:      12,
+		CursorPos: cursor,
+		Stdout:    console,
+	}
+
+	i, _, err := prompt.Run()
+
+	if err != nil {
+		if err == promptui.ErrInterrupt {
+			fmt.Fprintln(console, i18n.T("Canceled."))
+			os.Exit(1)
+		}
+
+		log.Panic(err)
+	}
+
+	switch {
+	case i < other:
+		return scopes[i]
+	case i == other:
+		return promptOrCancel(question, "")
+	default:
+		return ""
+	}
+}
@@ -2,8 +2,12 @@ package tmpl
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/jamesdobson/gogitmoji/gitmoji"
+	"github.com/jamesdobson/gogitmoji/history"
 )
 
 func TestGetPrintableCommand(t *testing.T) {
@@ -22,3 +26,43 @@ func TestGetPrintableCommand(t *testing.T) {
 	assert.Equal(`echo "He asked \"why not?\""`,
 		getPrintableCommand("echo", []string{`He asked "why not?"`}))
 }
+
+func TestMoveToFront(t *testing.T) {
+	list := []gitmoji.Gitmoji{{Code: ":art:"}, {Code: ":bug:"}, {Code: ":memo:"}, {Code: ":fire:"}}
+
+	assert.Equal(t, list, moveToFront(list, nil))
+	assert.Equal(t,
+		[]gitmoji.Gitmoji{{Code: ":memo:"}, {Code: ":bug:"}, {Code: ":art:"}, {Code: ":fire:"}},
+		moveToFront(list, []string{":memo:", ":unknown:", ":bug:"}))
+}
+
+func TestGitmojiRecorded(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	tpl := CommandTemplate{
+		Prompts: []Prompt{
+			{Type: "gitmoji", Name: "gitmoji", Mandatory: true},
+			{Type: "text", Name: "title", Mandatory: true},
+		},
+		Messages: []string{"{{.gitmoji.Emoji}} {{.title}}"},
+	}
+
+	// A gitmoji given in advance counts as chosen once the message is made
+	message := GetTemplateMessage(tpl, Options{
+		Answers: map[string]interface{}{"gitmoji": "bug", "title": "stop crashing"},
+		NoInput: true,
+	})
+
+	assert.Equal("🐛 stop crashing", message)
+
+	hist, err := history.New()
+	assert.NoError(err)
+
+	uses := hist.Uses(time.Now())
+	assert.Len(uses, 1)
+	assert.Equal(":bug:", uses[0].Code)
+	assert.Equal(1, uses[0].Count)
+}
@@ -0,0 +1,50 @@
+package tmpl
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/jamesdobson/gogitmoji/i18n"
+)
+
+// validateAnswer returns an error if the answer to a text prompt breaks one
+// of the prompt's rules. An optional prompt may always be left empty.
+func validateAnswer(question Prompt, answer string) error {
+	if answer == "" {
+		if question.Mandatory {
+			return errors.New(i18n.T("this is required"))
+		}
+
+		return nil
+	}
+
+	length := utf8.RuneCountInString(answer)
+
+	if question.MinLength > 0 && length < question.MinLength {
+		return fmt.Errorf(i18n.T("must be at least %d characters"), question.MinLength)
+	}
+
+	if question.MaxLength > 0 && length > question.MaxLength {
+		return fmt.Errorf(i18n.T("must be at most %d characters (this is %d)"), question.MaxLength, length)
+	}
+
+	if question.Pattern != "" {
+		re, err := regexp.Compile(question.Pattern)
+
+		if err != nil {
+			return fmt.Errorf("invalid pattern for '%s': %v", question.Name, err)
+		}
+
+		if !re.MatchString(answer) {
+			if question.PatternMessage != "" {
+				return errors.New(i18n.T(question.PatternMessage))
+			}
+
+			return fmt.Errorf(i18n.T("must match %s"), question.Pattern)
+		}
+	}
+
+	return nil
+}
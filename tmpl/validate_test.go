@@ -0,0 +1,43 @@
+package tmpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAnswer(t *testing.T) {
+	assert := assert.New(t)
+	title := Prompt{
+		Type:           "text",
+		Name:           "title",
+		Mandatory:      true,
+		MinLength:      3,
+		MaxLength:      10,
+		Pattern:        `[^.]$`,
+		PatternMessage: "must not end with a period",
+	}
+
+	assert.NoError(validateAnswer(title, "fix crash"))
+	assert.EqualError(validateAnswer(title, ""), "this is required")
+	assert.Error(validateAnswer(title, "ab"))
+	assert.Error(validateAnswer(title, "fix the big crash"))
+	assert.EqualError(validateAnswer(title, "fix crash."), "must not end with a period")
+
+	// Lengths are in characters, not bytes
+	assert.NoError(validateAnswer(title, "corrigir é"))
+
+	// An optional prompt may be left empty
+	title.Mandatory = false
+	assert.NoError(validateAnswer(title, ""))
+
+	title.PatternMessage = ""
+	assert.EqualError(validateAnswer(title, "fix crash."), "must match [^.]$")
+}
+
+func TestPresetAnswerValidation(t *testing.T) {
+	title := Prompt{Type: "text", Name: "title", MaxLength: 5}
+
+	_, err := presetAnswer(title, "too long")
+	assert.Error(t, err)
+}
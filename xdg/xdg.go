@@ -0,0 +1,98 @@
+// Package xdg locates gogitmoji's files according to the XDG Base Directory
+// Specification, falling back to the legacy ~/.gitmoji directory for files
+// that have not been moved from there yet.
+package xdg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// AppDirName is the name of gogitmoji's directory under each base directory.
+const AppDirName = "gitmoji"
+
+// LegacyDirName is the name of the directory under the user's home directory
+// where gogitmoji used to keep all its files.
+const LegacyDirName = ".gitmoji"
+
+// ConfigDir returns the directory for gogitmoji's configuration:
+// $XDG_CONFIG_HOME/gitmoji, or ~/.config/gitmoji if XDG_CONFIG_HOME is unset.
+func ConfigDir() (string, error) {
+	return baseDir("XDG_CONFIG_HOME", ".config")
+}
+
+// CacheDir returns the directory for gogitmoji's cached data:
+// $XDG_CACHE_HOME/gitmoji, or ~/.cache/gitmoji if XDG_CACHE_HOME is unset.
+func CacheDir() (string, error) {
+	return baseDir("XDG_CACHE_HOME", ".cache")
+}
+
+// StateDir returns the directory for gogitmoji's state, such as its history:
+// $XDG_STATE_HOME/gitmoji, or ~/.local/state/gitmoji if XDG_STATE_HOME is
+// unset.
+func StateDir() (string, error) {
+	return baseDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// LegacyDir returns the directory where gogitmoji used to keep all its files.
+func LegacyDir() (string, error) {
+	home, err := os.UserHomeDir()
+
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %v", err)
+	}
+
+	return filepath.Join(home, LegacyDirName), nil
+}
+
+// Locate returns the path of the named file in the given directory. If the
+// file does not exist there, but does exist in the legacy directory, then the
+// path in the legacy directory is returned instead, along with true.
+func Locate(dir string, name string) (string, bool, error) {
+	preferred := filepath.Join(dir, name)
+
+	if exists(preferred) {
+		return preferred, false, nil
+	}
+
+	legacyDir, err := LegacyDir()
+
+	if err != nil {
+		return "", false, err
+	}
+
+	legacy := filepath.Join(legacyDir, name)
+
+	if exists(legacy) {
+		return legacy, true, nil
+	}
+
+	return preferred, false, nil
+}
+
+// baseDir returns gogitmoji's directory under the base directory given by the
+// environment variable, or under the default directory in the user's home
+// directory if the variable is unset. As the specification requires, relative
+// paths in the variable are ignored.
+func baseDir(variable string, defaultDir string) (string, error) {
+	base := os.Getenv(variable)
+
+	if base == "" || !filepath.IsAbs(base) {
+		home, err := os.UserHomeDir()
+
+		if err != nil {
+			return "", fmt.Errorf("cannot determine home directory: %v", err)
+		}
+
+		base = filepath.Join(home, defaultDir)
+	}
+
+	return filepath.Join(base, AppDirName), nil
+}
+
+func exists(file string) bool {
+	_, err := os.Stat(file)
+
+	return err == nil
+}
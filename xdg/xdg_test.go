@@ -0,0 +1,79 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseDirs(t *testing.T) {
+	assert := assert.New(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_CACHE_HOME", "relative/paths/are/ignored")
+	t.Setenv("XDG_STATE_HOME", "")
+
+	dir, err := ConfigDir()
+	assert.NoError(err)
+	assert.Equal(filepath.Join(home, ".config", "gitmoji"), dir)
+
+	dir, err = CacheDir()
+	assert.NoError(err)
+	assert.Equal(filepath.Join(home, ".cache", "gitmoji"), dir)
+
+	dir, err = StateDir()
+	assert.NoError(err)
+	assert.Equal(filepath.Join(home, ".local", "state", "gitmoji"), dir)
+
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	t.Setenv("XDG_CACHE_HOME", "/xdg/cache")
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+
+	dir, err = ConfigDir()
+	assert.NoError(err)
+	assert.Equal(filepath.Join("/xdg/config", "gitmoji"), dir)
+
+	dir, err = CacheDir()
+	assert.NoError(err)
+	assert.Equal(filepath.Join("/xdg/cache", "gitmoji"), dir)
+
+	dir, err = StateDir()
+	assert.NoError(err)
+	assert.Equal(filepath.Join("/xdg/state", "gitmoji"), dir)
+}
+
+func TestLocate(t *testing.T) {
+	assert := assert.New(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, "xdg", "gitmoji")
+	legacyDir := filepath.Join(home, LegacyDirName)
+
+	// Neither exists: use the preferred location
+	file, legacy, err := Locate(dir, "gitmojis.json")
+	assert.NoError(err)
+	assert.False(legacy)
+	assert.Equal(filepath.Join(dir, "gitmojis.json"), file)
+
+	// Only the legacy file exists
+	assert.NoError(os.MkdirAll(legacyDir, 0755))
+	assert.NoError(os.WriteFile(filepath.Join(legacyDir, "gitmojis.json"), []byte("{}"), 0600))
+
+	file, legacy, err = Locate(dir, "gitmojis.json")
+	assert.NoError(err)
+	assert.True(legacy)
+	assert.Equal(filepath.Join(legacyDir, "gitmojis.json"), file)
+
+	// Both exist: the preferred location wins
+	assert.NoError(os.MkdirAll(dir, 0755))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "gitmojis.json"), []byte("{}"), 0600))
+
+	file, legacy, err = Locate(dir, "gitmojis.json")
+	assert.NoError(err)
+	assert.False(legacy)
+	assert.Equal(filepath.Join(dir, "gitmojis.json"), file)
+}